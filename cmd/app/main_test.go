@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"l0/internal/config"
+	"l0/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartupWarmupWindow(t *testing.T) {
+	tests := []struct {
+		mode string
+		want service.WarmupWindow
+	}{
+		{mode: config.WarmupModeLast, want: service.WarmupWindow{Limit: 50}},
+		{mode: config.WarmupModeRecent, want: service.WarmupWindow{Since: 2 * time.Hour}},
+		{mode: config.WarmupModeAll, want: service.WarmupWindow{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := &config.Config{CacheWarmupMode: tt.mode, CacheWarmupLimit: 50, CacheWarmupSince: 2 * time.Hour}
+			assert.Equal(t, tt.want, startupWarmupWindow(cfg))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Команда export выгружает заказы из PostgreSQL в CSV, NDJSON или XLSX.
+//
+//	go run ./cmd/export -format xlsx -from 2024-01-01T00:00:00Z -out orders.xlsx
+//
+// Адрес БД берётся из DATABASE_URL, как и у основного сервиса.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"l0/internal/auth"
+	"l0/internal/config"
+	"l0/internal/db"
+	"l0/internal/export"
+	"l0/internal/models"
+	"l0/internal/redact"
+)
+
+func main() {
+	var (
+		format  = flag.String("format", "csv", "формат выгрузки: csv, ndjson, xlsx")
+		out     = flag.String("out", "", "файл для выгрузки (по умолчанию stdout)")
+		sort    = flag.String("sort", "-date", "сортировка: date, amount, префикс - для убывания")
+		limit   = flag.Int("limit", 0, "максимальное число заказов (0 - без ограничения)")
+		from    = flag.String("from", "", "начало периода date_created (RFC3339)")
+		to      = flag.String("to", "", "конец периода date_created (RFC3339, не включая)")
+		role    = flag.String("role", string(auth.RoleAnalytics), "роль, определяющая видимость персональных данных: admin, support, analytics")
+		verbose = flag.Bool("v", false, "печатать прогресс в stderr")
+		filter  db.OrderFilter
+	)
+	flag.StringVar(&filter.CustomerID, "customer-id", "", "фильтр по customer_id")
+	flag.StringVar(&filter.TrackNumber, "track-number", "", "фильтр по трек-номеру заказа")
+	flag.StringVar(&filter.DeliveryService, "delivery-service", "", "фильтр по службе доставки")
+	flag.StringVar(&filter.PaymentProvider, "provider", "", "фильтр по платёжному провайдеру")
+	flag.StringVar(&filter.Currency, "currency", "", "фильтр по валюте оплаты")
+	flag.StringVar(&filter.ItemBrand, "brand", "", "фильтр по бренду товара")
+	flag.Parse()
+
+	exportFormat, err := export.ParseFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	exportRole, err := auth.ParseRole(*role)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if filter.CreatedFrom, err = parseTime(*from); err != nil {
+		log.Fatalf("invalid -from: %v", err)
+	}
+	if filter.CreatedTo, err = parseTime(*to); err != nil {
+		log.Fatalf("invalid -to: %v", err)
+	}
+
+	params := db.ListOrdersParams{Filter: filter, Limit: *limit}
+	if params.Sort, params.Desc, err = db.ParseSort(*sort); err != nil {
+		log.Fatalf("invalid -sort: %v", err)
+	}
+
+	if err := run(params, exportFormat, exportRole, *out, *verbose); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(params db.ListOrdersParams, format export.Format, role auth.Role, out string, verbose bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dbService, err := db.NewPostgres(ctx, config.DatabaseURL())
+	if err != nil {
+		return err
+	}
+	defer dbService.Close()
+
+	dst := os.Stdout
+	if out != "" {
+		if dst, err = os.Create(out); err != nil {
+			return fmt.Errorf("failed to create output file: %v", err)
+		}
+		defer dst.Close()
+	}
+
+	writer, err := export.NewWriter(format, dst)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	err = dbService.StreamOrders(ctx, params, func(order models.Order) error {
+		count++
+		if verbose && count%db.StreamPageSize == 0 {
+			log.Printf("Exported %d orders", count)
+		}
+		return writer.Write(redact.Order(order, role))
+	})
+	if err != nil {
+		return fmt.Errorf("export failed after %d orders: %v", count, err)
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	if out != "" {
+		if err := dst.Sync(); err != nil {
+			return err
+		}
+	}
+	log.Printf("Exported %d orders", count)
+	return nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
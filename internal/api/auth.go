@@ -0,0 +1,122 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"l0/internal/auth"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// WithAuth включает проверку API-ключей и JWT. Без неё API доступен без аутентификации.
+func WithAuth(authenticator *auth.Authenticator) Option {
+	return func(h *Handler) {
+		h.auth = authenticator
+	}
+}
+
+// WithCORS задаёт origin'ы, которым браузер разрешит обращаться к API.
+// "*" разрешает любой origin. Без этой опции CORS-заголовки не отправляются.
+func WithCORS(origins []string) Option {
+	return func(h *Handler) {
+		h.corsOrigins = make(map[string]bool, len(origins))
+		for _, origin := range origins {
+			h.corsOrigins[strings.TrimSuffix(origin, "/")] = true
+		}
+	}
+}
+
+// requireScope пропускает запрос, только если клиент аутентифицирован и имеет scope.
+// queryToken разрешает передать токен в параметре access_token: EventSource и
+// WebSocket в браузере не умеют задавать заголовки.
+func (h *Handler) requireScope(scope auth.Scope, queryToken bool, next http.HandlerFunc) http.HandlerFunc {
+	if h.auth == nil || scope == "" {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := h.auth.Authenticate(credentialFromRequest(r, queryToken))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="orders"`)
+			if errors.Is(err, auth.ErrNoCredentials) {
+				writeError(w, http.StatusUnauthorized, "Authentication required")
+			} else {
+				writeError(w, http.StatusUnauthorized, "Invalid credentials")
+			}
+			return
+		}
+		if !principal.HasScope(scope) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="orders", error="insufficient_scope", scope="`+string(scope)+`"`)
+			writeError(w, http.StatusForbidden, "Scope "+string(scope)+" is required")
+			return
+		}
+		next(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+func credentialFromRequest(r *http.Request, queryToken bool) string {
+	if value := r.Header.Get("Authorization"); value != "" {
+		scheme, credential, _ := strings.Cut(value, " ")
+		if strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(credential)
+		}
+		return ""
+	}
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	if queryToken {
+		return r.URL.Query().Get("access_token")
+	}
+	return ""
+}
+
+var (
+	corsAllowMethods  = strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete, http.MethodOptions}, ", ")
+	corsAllowHeaders  = strings.Join([]string{"Authorization", "Content-Type", apiKeyHeader, idempotencyKeyHeader, requestIDHeader, "If-None-Match", "If-Modified-Since", "Last-Event-ID"}, ", ")
+	corsExposeHeaders = strings.Join([]string{"ETag", "Last-Modified", "Idempotent-Replayed", "Retry-After", requestIDHeader}, ", ")
+)
+
+const corsMaxAge = 600
+
+func (h *Handler) originAllowed(origin string) bool {
+	return h.corsOrigins["*"] || h.corsOrigins[origin]
+}
+
+// applyCORS выставляет CORS-заголовки и сообщает, был ли запрос preflight-запросом,
+// на который ответ уже отправлен.
+func (h *Handler) applyCORS(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	if origin == "" || !h.originAllowed(origin) {
+		return false
+	}
+
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", corsAllowMethods)
+		header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+		header.Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+		w.WriteHeader(http.StatusNoContent)
+		return true
+	}
+	return false
+}
+
+// checkWebSocketOrigin пропускает клиентов без Origin (не браузеры), запросы с того же хоста
+// и origin'ы из списка CORS.
+func (h *Handler) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || h.originAllowed(origin) {
+		return true
+	}
+	_, host, found := strings.Cut(origin, "://")
+	return found && strings.EqualFold(host, r.Host)
+}
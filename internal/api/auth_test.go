@@ -0,0 +1,164 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"l0/internal/auth"
+	"l0/internal/cache"
+	"l0/internal/db"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testJWTSecret = []byte("jwt-secret")
+
+func newAuthTestHandler(t *testing.T, opts ...Option) (http.Handler, *cache.MockCache) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	mockCache := cache.NewMockCache(ctrl)
+	authenticator := auth.New(auth.Config{
+		APIKeys: map[string]auth.Principal{
+			"reader-key":  {Subject: "reports", Scopes: []auth.Scope{auth.ScopeOrdersRead}, Role: auth.RoleAnalytics},
+			"support-key": {Subject: "crm", Scopes: []auth.Scope{auth.ScopeOrdersRead}, Role: auth.RoleSupport},
+			"admin-key":   {Subject: "ops", Scopes: []auth.Scope{auth.ScopeAdmin}, Role: auth.RoleAdmin},
+		},
+		HMACSecret: testJWTSecret,
+	})
+	opts = append([]Option{WithAuth(authenticator)}, opts...)
+	return NewHandler(mockCache, db.NewMockDatabase(ctrl), opts...), mockCache
+}
+
+func requestWith(h http.Handler, method, target string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader("{}"))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func testToken(t *testing.T, scope string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "svc",
+		"scope": scope,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString(testJWTSecret)
+	require.NoError(t, err)
+	return token
+}
+
+func TestAuth_RejectsMissingAndInvalidCredentials(t *testing.T) {
+	h, _ := newAuthTestHandler(t)
+
+	rec := requestWith(h, http.MethodGet, "/api/v1/orders/o-1", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+	assert.Equal(t, "Authentication required", decodeError(t, rec).Error)
+
+	rec = requestWith(h, http.MethodGet, "/api/v1/orders/o-1", map[string]string{"X-API-Key": "wrong"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = requestWith(h, http.MethodGet, "/order?uid=o-1", map[string]string{"Authorization": "Basic cmVhZGVyLWtleQ=="})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAuth_AcceptsAPIKeysAndTokens(t *testing.T) {
+	h, mockCache := newAuthTestHandler(t)
+	mockCache.EXPECT().Get("o-1").Return(testOrder("o-1"), true).Times(3)
+
+	for name, headers := range map[string]map[string]string{
+		"api key header": {"X-API-Key": "reader-key"},
+		"api key bearer": {"Authorization": "Bearer reader-key"},
+		"jwt":            {"Authorization": "Bearer " + testToken(t, "orders:read")},
+	} {
+		rec := requestWith(h, http.MethodGet, "/api/v1/orders/o-1", headers)
+		assert.Equal(t, http.StatusOK, rec.Code, name)
+	}
+}
+
+func TestAuth_EnforcesScopes(t *testing.T) {
+	h, _ := newAuthTestHandler(t)
+
+	rec := requestWith(h, http.MethodPost, "/api/v1/orders", map[string]string{"X-API-Key": "reader-key"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `scope="orders:write"`)
+
+	rec = requestWith(h, http.MethodPatch, "/api/v1/orders/o-1/status", map[string]string{
+		"Authorization": "Bearer " + testToken(t, "orders:read"),
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// admin включает orders:write; пустой заказ отклоняется уже валидацией.
+	rec = requestWith(h, http.MethodPost, "/api/v1/orders", map[string]string{"X-API-Key": "admin-key"})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestAuth_PublicRoutes(t *testing.T) {
+	h, _ := newAuthTestHandler(t)
+
+	rec := requestWith(h, http.MethodGet, "/api/openapi.json", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAuth_StreamAcceptsQueryToken(t *testing.T) {
+	h, _ := newAuthTestHandler(t)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/orders/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?access_token=reader-key", nil)
+	require.NoError(t, err)
+	conn.Close()
+
+	// Для обычных маршрутов токен в query-строке не принимается.
+	rec := requestWith(h, http.MethodGet, "/api/v1/orders/o-1?access_token=reader-key", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestCORS(t *testing.T) {
+	h, mockCache := newAuthTestHandler(t, WithCORS([]string{"https://ops.example.com"}))
+
+	preflight := requestWith(h, http.MethodOptions, "/api/v1/orders/o-1", map[string]string{
+		"Origin":                         "https://ops.example.com",
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "authorization",
+	})
+	assert.Equal(t, http.StatusNoContent, preflight.Code)
+	assert.Equal(t, "https://ops.example.com", preflight.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, preflight.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	mockCache.EXPECT().Get("o-1").Return(testOrder("o-1"), true)
+	rec := requestWith(h, http.MethodGet, "/api/v1/orders/o-1", map[string]string{
+		"Origin":    "https://ops.example.com",
+		"X-API-Key": "reader-key",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://ops.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "ETag")
+
+	rec = requestWith(h, http.MethodGet, "/api/openapi.json", map[string]string{"Origin": "https://evil.example.com"})
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_DisabledByDefault(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	rec := requestWith(h, http.MethodGet, "/api/openapi.json", map[string]string{"Origin": "https://ops.example.com"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
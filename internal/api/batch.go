@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"l0/internal/auth"
+	"l0/internal/logging"
+	"l0/internal/models"
+	"l0/internal/redact"
+	"l0/internal/service"
+)
+
+type batchGetRequest struct {
+	OrderUIDs []string `json:"order_uids"`
+}
+
+type batchGetResponse struct {
+	Orders   []models.Order `json:"orders"`
+	NotFound []string       `json:"not_found"`
+}
+
+// batchGetOrders отдаёт до service.MaxBatchGet заказов за один запрос.
+func (h *Handler) batchGetOrders(w http.ResponseWriter, r *http.Request) {
+	var req batchGetRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxIngestBodyBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(req.OrderUIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "order_uids must not be empty")
+		return
+	}
+	if len(req.OrderUIDs) > service.MaxBatchGet {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("order_uids must contain at most %d elements", service.MaxBatchGet))
+		return
+	}
+
+	orders, notFound, err := h.orders.BatchGet(r.Context(), req.OrderUIDs)
+	if writeRateLimited(w, err) {
+		return
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to batch get orders", "count", len(req.OrderUIDs), "error", err)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if notFound == nil {
+		notFound = []string{}
+	}
+	orders = redact.Orders(orders, auth.RoleFromContext(r.Context()))
+	writeJSON(w, http.StatusOK, batchGetResponse{Orders: orders, NotFound: notFound})
+}
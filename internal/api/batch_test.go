@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"l0/internal/models"
+	"l0/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchGet(h http.Handler, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/orders:batchGet", strings.NewReader(body)))
+	return rec
+}
+
+func TestHandler_BatchGetOrders(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	mockCache.EXPECT().Get("b-1").Return(testOrder("b-1"), true)
+	mockCache.EXPECT().Get("b-2").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("b-2").Return(false)
+	mockCache.EXPECT().Get("b-3").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("b-3").Return(false)
+	mockCache.EXPECT().Get("b-4").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("b-4").Return(true)
+	mockDB.EXPECT().GetOrders(gomock.Any(), []string{"b-2", "b-3"}).Return([]models.Order{testOrder("b-2")}, nil)
+	mockCache.EXPECT().Set("b-2", gomock.Any())
+	mockCache.EXPECT().MarkMissing("b-3")
+
+	rec := batchGet(h, `{"order_uids": ["b-1", "b-2", "b-3", "b-4", "b-1"]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body batchGetResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Len(t, body.Orders, 2)
+	assert.Equal(t, "b-1", body.Orders[0].OrderUID)
+	assert.Equal(t, "b-2", body.Orders[1].OrderUID)
+	assert.Equal(t, []string{"b-3", "b-4"}, body.NotFound)
+}
+
+func TestHandler_BatchGetOrdersAllCached(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+
+	mockCache.EXPECT().Get("b-1").Return(testOrder("b-1"), true)
+
+	rec := batchGet(h, `{"order_uids": ["b-1"]}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"not_found":[]`)
+}
+
+func TestHandler_BatchGetOrdersInvalidRequest(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	tooMany := make([]string, service.MaxBatchGet+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("%q", fmt.Sprint("uid-", i))
+	}
+
+	for name, body := range map[string]string{
+		"malformed": `{"order_uids": `,
+		"empty":     `{"order_uids": []}`,
+		"too many":  `{"order_uids": [` + strings.Join(tooMany, ",") + `]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := batchGet(h, body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestHandler_BatchGetOrdersDBError(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	mockCache.EXPECT().Get("b-1").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("b-1").Return(false)
+	mockDB.EXPECT().GetOrders(gomock.Any(), []string{"b-1"}).Return(nil, assert.AnError)
+
+	rec := batchGet(h, `{"order_uids": ["b-1"]}`)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
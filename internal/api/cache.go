@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/logging"
+	"l0/internal/service"
+)
+
+// WithWarmer задаёт прогрев кэша, общий с прогревом при старте, чтобы ручной
+// перезапуск не шёл параллельно со стартовым.
+func WithWarmer(warmer *service.Warmer) Option {
+	return func(h *Handler) {
+		h.warmer = warmer
+	}
+}
+
+type cacheWarmupResponse struct {
+	Running bool `json:"running"`
+	Limit   int  `json:"limit"`
+	// Since - окно по времени создания заказа в формате Go duration, пустое - без ограничения.
+	Since      string     `json:"since,omitempty"`
+	Flush      bool       `json:"flush"`
+	Loaded     int        `json:"loaded"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type cacheStatsResponse struct {
+	Cache  cache.Stats         `json:"cache"`
+	Warmup cacheWarmupResponse `json:"warmup"`
+}
+
+type cacheFlushResponse struct {
+	Removed int `json:"removed"`
+}
+
+func newCacheWarmupResponse(status service.WarmupStatus) cacheWarmupResponse {
+	resp := cacheWarmupResponse{
+		Running: status.Running,
+		Limit:   status.Window.Limit,
+		Flush:   status.Window.Flush,
+		Loaded:  status.Loaded,
+	}
+	if status.Window.Since > 0 {
+		resp.Since = status.Window.Since.String()
+	}
+	if !status.StartedAt.IsZero() {
+		resp.StartedAt = &status.StartedAt
+	}
+	if !status.FinishedAt.IsZero() {
+		resp.FinishedAt = &status.FinishedAt
+	}
+	if status.Err != nil {
+		resp.Error = status.Err.Error()
+	}
+	return resp
+}
+
+// getCacheStats показывает размер кэша по шардам, долю попаданий и состояние прогрева.
+func (h *Handler) getCacheStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, cacheStatsResponse{
+		Cache:  h.cacheService.Stats(),
+		Warmup: newCacheWarmupResponse(h.warmer.Status()),
+	})
+}
+
+// getCacheEntry отдаёт заказ ровно в том виде, в каком он лежит в кэше, без обращения к БД
+// и без учёта в статистике попаданий.
+func (h *Handler) getCacheEntry(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.cacheService.Peek(r.PathValue("uid"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Order is not cached")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, order)
+}
+
+func (h *Handler) evictCacheEntry(w http.ResponseWriter, r *http.Request) {
+	uid := r.PathValue("uid")
+	if _, ok := h.cacheService.Peek(uid); !ok {
+		writeError(w, http.StatusNotFound, "Order is not cached")
+		return
+	}
+	h.cacheService.Remove(uid)
+	logging.FromContext(r.Context()).Info("Order evicted from cache", "order_uid", uid)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) flushCache(w http.ResponseWriter, r *http.Request) {
+	removed := h.cacheService.Clear()
+	logging.FromContext(r.Context()).Info("Cache flushed", "orders", removed)
+	writeJSON(w, http.StatusOK, cacheFlushResponse{Removed: removed})
+}
+
+// rewarmCache запускает прогрев кэша из PostgreSQL в фоне и сразу отвечает 202;
+// ход прогрева показывает GET /api/v1/admin/cache.
+func (h *Handler) rewarmCache(w http.ResponseWriter, r *http.Request) {
+	window, err := parseWarmupWindow(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Прогрев переживает запрос, но сохраняет его логгер с request_id.
+	err = h.warmer.Start(context.WithoutCancel(r.Context()), window)
+	if errors.Is(err, service.ErrWarmupRunning) {
+		writeError(w, http.StatusConflict, "Cache warm-up is already running")
+		return
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to start cache warm-up", "error", err)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, newCacheWarmupResponse(h.warmer.Status()))
+}
+
+// parseWarmupWindow разбирает limit (число заказов), since (Go duration, например 24h)
+// и flush. Без параметров используется окно прогрева при старте.
+func parseWarmupWindow(query url.Values) (service.WarmupWindow, error) {
+	window := service.DefaultWarmupWindow
+	if query.Has("limit") || query.Has("since") {
+		window = service.WarmupWindow{}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return window, errors.New("limit must be a non-negative integer")
+		}
+		window.Limit = limit
+	}
+	if v := query.Get("since"); v != "" {
+		since, err := time.ParseDuration(v)
+		if err != nil || since < 0 {
+			return window, errors.New("since must be a non-negative duration such as 24h")
+		}
+		window.Since = since
+	}
+	if v := query.Get("flush"); v != "" {
+		flush, err := strconv.ParseBool(v)
+		if err != nil {
+			return window, errors.New("flush must be true or false")
+		}
+		window.Flush = flush
+	}
+	return window, nil
+}
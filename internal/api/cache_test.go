@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeCacheState(t *testing.T, body []byte) cacheStatsResponse {
+	t.Helper()
+	var state cacheStatsResponse
+	require.NoError(t, json.Unmarshal(body, &state))
+	return state
+}
+
+func TestAdminCache_RequiresAdminScope(t *testing.T) {
+	h, _ := newAuthTestHandler(t)
+
+	rec := requestWith(h, http.MethodDelete, "/api/v1/admin/cache", map[string]string{apiKeyHeader: "reader-key"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestAdminCache_Stats(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	mockCache.EXPECT().Stats().Return(cache.Stats{Orders: 3, Shards: []int{1, 2}, Hits: 3, Misses: 1, HitRatio: 0.75})
+
+	rec := serve(h, http.MethodGet, "/api/v1/admin/cache")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	state := decodeCacheState(t, rec.Body.Bytes())
+	assert.Equal(t, []int{1, 2}, state.Cache.Shards)
+	assert.Equal(t, 0.75, state.Cache.HitRatio)
+	assert.False(t, state.Warmup.Running)
+	assert.Nil(t, state.Warmup.StartedAt)
+}
+
+func TestAdminCache_EntryAndEvict(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	order := testOrder("cached-1")
+	mockCache.EXPECT().Peek("cached-1").Return(order, true).Times(2)
+	mockCache.EXPECT().Peek("missing").Return(models.Order{}, false).Times(2)
+	mockCache.EXPECT().Remove("cached-1")
+
+	rec := serve(h, http.MethodGet, "/api/v1/admin/cache/orders/cached-1")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got models.Order
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, order.Delivery.Phone, got.Delivery.Phone, "raw entry is not redacted")
+
+	assert.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, "/api/v1/admin/cache/orders/missing").Code)
+	assert.Equal(t, http.StatusNoContent, serve(h, http.MethodDelete, "/api/v1/admin/cache/orders/cached-1").Code)
+	assert.Equal(t, http.StatusNotFound, serve(h, http.MethodDelete, "/api/v1/admin/cache/orders/missing").Code)
+}
+
+func TestAdminCache_Flush(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	mockCache.EXPECT().Clear().Return(5)
+
+	rec := serve(h, http.MethodDelete, "/api/v1/admin/cache")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"removed": 5}`, rec.Body.String())
+}
+
+func TestAdminCache_Rewarm(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	release := make(chan struct{})
+	mockCache.EXPECT().Clear().Return(1)
+	mockCache.EXPECT().Stats().Return(cache.Stats{Orders: 2}).AnyTimes()
+	mockCache.EXPECT().Set("warm-1", gomock.Any())
+	mockCache.EXPECT().Set("warm-2", gomock.Any())
+	mockDB.EXPECT().StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params db.ListOrdersParams, fn func(models.Order) error) error {
+			assert.Equal(t, 100, params.Limit)
+			assert.True(t, params.Desc)
+			assert.WithinDuration(t, time.Now().Add(-24*time.Hour), params.Filter.CreatedFrom, time.Minute)
+			<-release
+			for _, uid := range []string{"warm-1", "warm-2"} {
+				if err := fn(testOrder(uid)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	rec := serve(h, http.MethodPost, "/api/v1/admin/cache/rewarm?limit=100&since=24h&flush=true")
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	var started cacheWarmupResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &started))
+	assert.True(t, started.Running)
+	assert.Equal(t, "24h0m0s", started.Since)
+
+	rec = serve(h, http.MethodPost, "/api/v1/admin/cache/rewarm")
+	assert.Equal(t, http.StatusConflict, rec.Code, "only one warm-up at a time")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		state := decodeCacheState(t, serve(h, http.MethodGet, "/api/v1/admin/cache").Body.Bytes())
+		return !state.Warmup.Running && state.Warmup.Loaded == 2 && state.Warmup.FinishedAt != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestAdminCache_RewarmRejectsInvalidWindow(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	for _, query := range []string{"limit=-1", "limit=abc", "since=yesterday", "since=-1h", "flush=maybe"} {
+		rec := serve(h, http.MethodPost, "/api/v1/admin/cache/rewarm?"+query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"compress/gzip"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minCompressSize - ответы меньше этого размера отдаются без сжатия:
+// заголовки gzip съедают весь выигрыш.
+const minCompressSize = 1024
+
+var compressibleTypes = map[string]bool{
+	"application/json":       true,
+	"application/x-ndjson":   true,
+	"application/javascript": true,
+	"text/css":               true,
+	"text/csv":               true,
+	"text/html":              true,
+	"text/plain":             true,
+}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		gz, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return gz
+	},
+}
+
+// acceptsGzip разбирает Accept-Encoding с учётом q-значений. Явная запись gzip
+// важнее "*": "*" учитывается, только если gzip не указан.
+func acceptsGzip(r *http.Request) bool {
+	gzipQ, wildcardQ := -1.0, -1.0
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+		if coding == "gzip" {
+			gzipQ = q
+		} else {
+			wildcardQ = q
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return wildcardQ > 0
+}
+
+// compressWriter сжимает ответ gzip, если тип содержимого это позволяет и тело
+// набирает minCompressSize. До этого момента данные копятся в буфере, чтобы
+// маленькие ответы уходили как есть.
+type compressWriter struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+	passthrough bool
+	buf         []byte
+	gz          *gzip.Writer
+}
+
+func newCompressWriter(w http.ResponseWriter) *compressWriter {
+	return &compressWriter{ResponseWriter: w}
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+
+	if !cw.compressible(status) {
+		cw.passthrough = true
+		cw.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (cw *compressWriter) compressible(status int) bool {
+	if status < http.StatusOK || status == http.StatusNoContent ||
+		status == http.StatusNotModified || status == http.StatusPartialContent {
+		return false
+	}
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && compressibleTypes[mediaType]
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.passthrough {
+		return cw.ResponseWriter.Write(p)
+	}
+	if cw.gz != nil {
+		return cw.gz.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= minCompressSize {
+		if err := cw.startGzip(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (cw *compressWriter) startGzip() error {
+	header := cw.Header()
+	header.Del("Content-Length")
+	header.Set("Content-Encoding", "gzip")
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	cw.gz = gzipWriters.Get().(*gzip.Writer)
+	cw.gz.Reset(cw.ResponseWriter)
+	buf := cw.buf
+	cw.buf = nil
+	_, err := cw.gz.Write(buf)
+	return err
+}
+
+// Flush отправляет клиенту всё накопленное; потоковые ответы сжимаются сразу.
+func (cw *compressWriter) Flush() {
+	if cw.wroteHeader && !cw.passthrough && cw.gz == nil {
+		if err := cw.startGzip(); err != nil {
+			slog.Error("Failed to compress response", "error", err)
+			return
+		}
+	}
+	if cw.gz != nil {
+		if err := cw.gz.Flush(); err != nil {
+			slog.Error("Failed to compress response", "error", err)
+			return
+		}
+	}
+	if err := http.NewResponseController(cw.ResponseWriter).Flush(); err != nil && err != http.ErrNotSupported {
+		slog.Warn("Failed to flush response", "error", err)
+	}
+}
+
+// Close дописывает ответ: либо закрывает gzip-поток, либо отдаёт буфер без сжатия.
+func (cw *compressWriter) Close() {
+	switch {
+	case cw.gz != nil:
+		if err := cw.gz.Close(); err != nil {
+			slog.Error("Failed to compress response", "error", err)
+		}
+		cw.gz.Reset(nil)
+		gzipWriters.Put(cw.gz)
+		cw.gz = nil
+	case cw.wroteHeader && !cw.passthrough:
+		cw.ResponseWriter.WriteHeader(cw.status)
+		if len(cw.buf) > 0 {
+			if _, err := cw.ResponseWriter.Write(cw.buf); err != nil {
+				slog.Warn("Failed to write response", "error", err)
+			}
+		}
+	}
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"l0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func largeTestOrder(uid string) models.Order {
+	order := testOrder(uid)
+	for i := 0; i < 50; i++ {
+		order.Items = append(order.Items, models.Item{ChrtID: i + 2, Name: fmt.Sprintf("Item %d", i), Price: 100})
+	}
+	return order
+}
+
+func TestHandler_CompressesLargeResponses(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	mockCache.EXPECT().Get("gz-1").Return(largeTestOrder("gz-1"), true)
+
+	rec := conditionalGet(h, "/api/v1/orders/gz-1", map[string]string{"Accept-Encoding": "br, gzip"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+
+	gz, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(gz)
+	require.NoError(t, err)
+
+	var order models.Order
+	require.NoError(t, json.Unmarshal(raw, &order))
+	assert.Equal(t, "gz-1", order.OrderUID)
+	assert.Len(t, order.Items, 51)
+}
+
+func TestHandler_SkipsCompression(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		order          models.Order
+	}{
+		{"not accepted", "", largeTestOrder("gz-2")},
+		{"disabled by q", "gzip;q=0, identity", largeTestOrder("gz-2")},
+		{"small body", "gzip", testOrder("gz-2")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mockCache, _ := newTestHandler(t)
+			mockCache.EXPECT().Get("gz-2").Return(tt.order, true)
+
+			rec := conditionalGet(h, "/api/v1/orders/gz-2", map[string]string{"Accept-Encoding": tt.acceptEncoding})
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+			var order models.Order
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&order))
+			assert.Equal(t, "gz-2", order.OrderUID)
+		})
+	}
+}
+
+func TestHandler_NotModifiedIsNotCompressed(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	order := largeTestOrder("gz-3")
+	mockCache.EXPECT().Get("gz-3").Return(order, true).Times(2)
+
+	etag := conditionalGet(h, "/api/v1/orders/gz-3", nil).Header().Get("ETag")
+	rec := conditionalGet(h, "/api/v1/orders/gz-3", map[string]string{
+		"Accept-Encoding": "gzip",
+		"If-None-Match":   etag,
+	})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Zero(t, rec.Body.Len())
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for header, want := range map[string]bool{
+		"":                  false,
+		"gzip":              true,
+		"GZIP":              true,
+		"deflate, gzip;q=1": true,
+		"gzip;q=0":          false,
+		"*":                 true,
+		"identity":          false,
+		"*, gzip;q=0":       false,
+		"*;q=0, gzip":       true,
+		"*;q=0":             false,
+		"br, *;q=0.5":       true,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", header)
+		assert.Equal(t, want, acceptsGzip(req), header)
+	}
+}
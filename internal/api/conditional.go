@@ -0,0 +1,103 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"l0/internal/logging"
+	"l0/internal/models"
+)
+
+// writeOrderResource отдаёт представление заказа (целиком или его часть) с валидаторами
+// для условных запросов: слабый ETag считается по телу ответа, Last-Modified берётся из
+// времени последнего изменения заказа. Если клиентская копия актуальна, ответ - 304.
+func writeOrderResource(w http.ResponseWriter, r *http.Request, order *models.Order, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to encode response", "error", err)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	body = append(body, '\n')
+
+	etag := contentETag(body)
+	lastModified := orderLastModified(order)
+
+	header := w.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "private, no-cache")
+	// Представление зависит от роли клиента.
+	header.Add("Vary", "Authorization")
+	header.Add("Vary", apiKeyHeader)
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := w.Write(body); err != nil {
+		logging.FromContext(r.Context()).Warn("Failed to write response", "error", err)
+	}
+}
+
+// contentETag возвращает слабый ETag: тело может уйти клиенту сжатым gzip
+// (см. compressWriter), а сильный валидатор обязан различать такие представления.
+func contentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// orderLastModified возвращает время последнего изменения заказа с точностью до секунды,
+// как того требует формат заголовка. Для заказов без updated_at - время создания.
+func orderLastModified(order *models.Order) time.Time {
+	t := order.UpdatedAt
+	if t.IsZero() {
+		t = order.DateCreated
+	}
+	return t.UTC().Truncate(time.Second)
+}
+
+// notModified проверяет предусловия по RFC 9110: If-None-Match имеет приоритет,
+// If-Modified-Since учитывается только в его отсутствие.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
+
+// etagMatches выполняет слабое сравнение ETag из списка If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
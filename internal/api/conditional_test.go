@@ -0,0 +1,90 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func conditionalGet(h http.Handler, target string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_OrderValidators(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	order := testOrder("etag-1")
+	order.UpdatedAt = time.Date(2024, 2, 3, 4, 5, 6, 700, time.UTC)
+	mockCache.EXPECT().Get("etag-1").Return(order, true).Times(2)
+
+	first := conditionalGet(h, "/api/v1/orders/etag-1", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `W/"`), "gzip and identity bodies share a weak ETag")
+	assert.Equal(t, "Sat, 03 Feb 2024 04:05:06 GMT", first.Header().Get("Last-Modified"))
+
+	second := conditionalGet(h, "/api/v1/orders/etag-1", nil)
+	assert.Equal(t, etag, second.Header().Get("ETag"), "ETag must be stable for unchanged order")
+}
+
+func TestHandler_OrderETagChangesWithContent(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	order := testOrder("etag-2")
+	mockCache.EXPECT().Get("etag-2").Return(order, true)
+	changed := order
+	changed.Status = "paid"
+	mockCache.EXPECT().Get("etag-2").Return(changed, true)
+
+	before := conditionalGet(h, "/api/v1/orders/etag-2", nil).Header().Get("ETag")
+	after := conditionalGet(h, "/api/v1/orders/etag-2", nil).Header().Get("ETag")
+	assert.NotEqual(t, before, after)
+}
+
+func TestHandler_OrderConditionalRequests(t *testing.T) {
+	order := testOrder("cond-1")
+	order.UpdatedAt = time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+
+	h, mockCache, _ := newTestHandler(t)
+	mockCache.EXPECT().Get("cond-1").Return(order, true)
+	etag := conditionalGet(h, "/api/v1/orders/cond-1/payment", nil).Header().Get("ETag")
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"matching etag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"strong form in list", map[string]string{"If-None-Match": `"other", ` + strings.TrimPrefix(etag, "W/")}, http.StatusNotModified},
+		{"wildcard", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"stale etag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"not modified since", map[string]string{"If-Modified-Since": "Sat, 03 Feb 2024 04:05:06 GMT"}, http.StatusNotModified},
+		{"modified since", map[string]string{"If-Modified-Since": "Sat, 03 Feb 2024 04:05:05 GMT"}, http.StatusOK},
+		{"malformed date", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+		{"etag takes precedence", map[string]string{
+			"If-None-Match":     `"other"`,
+			"If-Modified-Since": "Sat, 03 Feb 2024 04:05:06 GMT",
+		}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCache.EXPECT().Get("cond-1").Return(order, true)
+			rec := conditionalGet(h, "/api/v1/orders/cond-1/payment", tt.headers)
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			if tt.want == http.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
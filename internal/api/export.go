@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"l0/internal/auth"
+	"l0/internal/db"
+	"l0/internal/export"
+	"l0/internal/logging"
+	"l0/internal/models"
+	"l0/internal/ratelimit"
+	"l0/internal/redact"
+)
+
+// exportOrders выгружает заказы по тем же фильтрам и сортировке, что и список.
+// Заказы читаются из БД страницами и сразу пишутся в ответ; limit ограничивает
+// общее число заказов в выгрузке.
+func (h *Handler) exportOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := export.FormatCSV
+	if value := query.Get("format"); value != "" {
+		var err error
+		if format, err = export.ParseFormat(value); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		query.Del("limit")
+	}
+
+	params, err := parseListParams(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params.Limit = limit
+
+	if writeRateLimited(w, ratelimit.Charge(r.Context(), ratelimit.BudgetDB)) {
+		return
+	}
+	role := auth.RoleFromContext(r.Context())
+
+	// Заголовки отправляются только с первым заказом, чтобы ошибку первого
+	// запроса к БД ещё можно было вернуть обычным ответом.
+	var (
+		writer  export.Writer
+		started bool
+	)
+	start := func() (err error) {
+		started = true
+		filename := fmt.Sprintf("orders-%s.%s", time.Now().UTC().Format("20060102-150405"), format.Extension())
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+
+		writer, err = export.NewWriter(format, w)
+		return err
+	}
+
+	err = h.db.StreamOrders(r.Context(), params, func(order models.Order) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return writer.Write(redact.Order(order, role))
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	switch {
+	case !started && errors.Is(err, db.ErrInvalidCursor):
+		writeError(w, http.StatusBadRequest, "Invalid cursor")
+	case !started:
+		logging.FromContext(r.Context()).Error("Failed to export orders", "error", err)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+	default:
+		// Статус уже отправлен: обрываем соединение, чтобы клиент не принял
+		// обрезанную выгрузку за полную.
+		logging.FromContext(r.Context()).Error("Export of orders interrupted", "error", err)
+		panic(http.ErrAbortHandler)
+	}
+}
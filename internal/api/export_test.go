@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"testing"
+
+	"l0/internal/db"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func streamOrders(orders ...models.Order) func(context.Context, db.ListOrdersParams, func(models.Order) error) error {
+	return func(_ context.Context, _ db.ListOrdersParams, fn func(models.Order) error) error {
+		for _, order := range orders {
+			if err := fn(order); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestHandler_ExportOrdersCSV(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+
+	mockDB.EXPECT().
+		StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, params db.ListOrdersParams, fn func(models.Order) error) error {
+			assert.Equal(t, "cust-1", params.Filter.CustomerID)
+			assert.Equal(t, db.SortByAmount, params.Sort)
+			assert.Equal(t, 5000, params.Limit)
+			return streamOrders(testOrder("e-1"), testOrder("e-2"))(ctx, params, fn)
+		})
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders/export?customer_id=cust-1&sort=amount&limit=5000")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), ".csv")
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "order_uid", records[0][0])
+	assert.Equal(t, "e-1", records[1][0])
+	assert.Equal(t, "e-2", records[2][0])
+}
+
+func TestHandler_ExportOrdersEmpty(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+	mockDB.EXPECT().StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(streamOrders())
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders/export?format=xlsx")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", rec.Header().Get("Content-Type"))
+	assert.NotZero(t, rec.Body.Len())
+}
+
+func TestHandler_ExportOrdersInvalidParams(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	for _, target := range []string{
+		"/api/v1/orders/export?format=pdf",
+		"/api/v1/orders/export?limit=-1",
+		"/api/v1/orders/export?sort=name",
+	} {
+		rec := serve(h, http.MethodGet, target)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+}
+
+func TestHandler_ExportOrdersDBError(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+	mockDB.EXPECT().StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).Return(assert.AnError)
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders/export?format=ndjson")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestHandler_ExportOrdersAbortsOnMidStreamError(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+	mockDB.EXPECT().
+		StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ db.ListOrdersParams, fn func(models.Order) error) error {
+			require.NoError(t, fn(testOrder("e-1")))
+			return assert.AnError
+		})
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serve(h, http.MethodGet, "/api/v1/orders/export?format=ndjson")
+	})
+}
@@ -1,69 +1,274 @@
 package api
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"errors"
 	"net/http"
+	"strings"
+	"sync/atomic"
 
+	"l0/internal/auth"
 	"l0/internal/cache"
 	"l0/internal/db"
+	"l0/internal/events"
+	"l0/internal/health"
+	"l0/internal/logging"
+	"l0/internal/models"
+	"l0/internal/ratelimit"
+	"l0/internal/redact"
+	"l0/internal/service"
+
+	"github.com/gorilla/websocket"
 )
 
+const apiPrefix = "/api/v1"
+
 type Handler struct {
 	cacheService cache.Cache
 	db           db.Database
+	orders       *service.Orders
+	publisher    OrderPublisher
+	events       *events.Broker
+	// streams отменяется при остановке сервера и закрывает SSE и WebSocket подписки:
+	// http.Server.Shutdown не ждёт их сам и не отменяет контекст запроса.
+	streams     context.Context
+	idempotency *idempotencyStore
+	auth        *auth.Authenticator
+	limiter     *ratelimit.Limiter
+	readiness   *health.Readiness
+	warmer      *service.Warmer
+	// readyStatus - последний ответ /readyz, чтобы логировать только смену готовности.
+	readyStatus atomic.Value
+	corsOrigins map[string]bool
+	wsUpgrader  websocket.Upgrader
+	mux         *http.ServeMux
+	// routes - зарегистрированные маршруты API, по ним строится и проверяется OpenAPI-описание.
+	routes  []route
+	openAPI openAPIDoc
+}
+
+type Option func(*Handler)
+
+// WithPublisher включает приём заказов через Kafka: POST /api/v1/orders
+// публикует заказы в топик вместо прямой записи в БД.
+func WithPublisher(publisher OrderPublisher) Option {
+	return func(h *Handler) {
+		h.publisher = publisher
+	}
+}
+
+// WithOrderService задаёт общий с gRPC API сервис чтения заказов.
+func WithOrderService(orders *service.Orders) Option {
+	return func(h *Handler) {
+		h.orders = orders
+	}
+}
+
+// WithEvents задаёт брокер уведомлений, общий с консьюмером.
+// Без него обработчик использует собственный брокер.
+func WithEvents(broker *events.Broker) Option {
+	return func(h *Handler) {
+		h.events = broker
+	}
 }
 
-func NewHandler(cache cache.Cache, db db.Database) http.Handler {
-	return &Handler{
+// WithStreamContext задаёт контекст, отмена которого закрывает открытые потоки событий.
+func WithStreamContext(ctx context.Context) Option {
+	return func(h *Handler) {
+		h.streams = ctx
+	}
+}
+
+func NewHandler(cache cache.Cache, db db.Database, opts ...Option) http.Handler {
+	h := &Handler{
 		cacheService: cache,
 		db:           db,
+		idempotency:  newIdempotencyStore(idempotencyTTL, idempotencyMaxEntries),
+		mux:          http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	if h.orders == nil {
+		h.orders = service.NewOrders(cache, db)
+	}
+	if h.warmer == nil {
+		h.warmer = service.NewWarmer(cache, db)
+	}
+	if h.readiness == nil {
+		h.readiness = health.NewReadiness()
+	}
+	if h.events == nil {
+		h.events = events.NewBroker(events.DefaultReplaySize)
+	}
+	if h.streams == nil {
+		h.streams = context.Background()
+	}
+	h.wsUpgrader = websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin:     h.checkWebSocketOrigin,
+	}
+	h.registerRoutes()
+	return h
+}
+
+// route - маршрут API и scope, который нужен для обращения к нему.
+type route struct {
+	pattern string
+	scope   auth.Scope
+}
+
+func (h *Handler) registerRoutes() {
+	read, write := auth.ScopeOrdersRead, auth.ScopeOrdersWrite
+
+	h.handle("GET /api/openapi.json", "", h.getOpenAPI)
+	h.handle("GET /healthz", "", h.getHealthz)
+	h.handle("GET /readyz", "", h.getReadyz)
+	h.handle("GET "+apiPrefix+"/orders", read, h.listOrders)
+	h.handle("POST "+apiPrefix+"/orders", write, h.createOrders)
+	h.handle("POST "+apiPrefix+"/orders:batchGet", read, h.batchGetOrders)
+	h.handle("GET "+apiPrefix+"/orders/export", read, h.exportOrders)
+	h.handleStream("GET "+apiPrefix+"/orders/stream", read, h.streamOrders)
+	h.handleStream("GET "+apiPrefix+"/orders/ws", read, h.subscribeOrders)
+	h.handle("GET "+apiPrefix+"/orders/{uid}", read, h.getOrder)
+	h.handle("GET "+apiPrefix+"/orders/{uid}/items", read, h.getOrderItems)
+	h.handle("GET "+apiPrefix+"/orders/{uid}/payment", read, h.getOrderPayment)
+	h.handle("GET "+apiPrefix+"/orders/{uid}/delivery", read, h.getOrderDelivery)
+	h.handle("GET "+apiPrefix+"/orders/{uid}/status", read, h.getOrderStatus)
+	h.handle("PATCH "+apiPrefix+"/orders/{uid}/status", write, h.updateOrderStatus)
+	h.handle("GET "+apiPrefix+"/tracks/{track}/orders", read, h.getOrdersByTrackNumber)
+	h.handle("GET "+apiPrefix+"/customers/{customerID}/orders", read, h.getOrdersByCustomer)
+	h.handle("GET "+apiPrefix+"/admin/ratelimits", auth.ScopeAdmin, h.getRateLimits)
+	h.handle("GET "+apiPrefix+"/admin/cache", auth.ScopeAdmin, h.getCacheStats)
+	h.handle("DELETE "+apiPrefix+"/admin/cache", auth.ScopeAdmin, h.flushCache)
+	h.handle("POST "+apiPrefix+"/admin/cache/rewarm", auth.ScopeAdmin, h.rewarmCache)
+	h.handle("GET "+apiPrefix+"/admin/cache/orders/{uid}", auth.ScopeAdmin, h.getCacheEntry)
+	h.handle("DELETE "+apiPrefix+"/admin/cache/orders/{uid}", auth.ScopeAdmin, h.evictCacheEntry)
+
+	// Старый эндпоинт оставлен для совместимости с существующими клиентами.
+	h.handle("GET /order", read, h.getOrderLegacy)
+
+	h.mux.HandleFunc("/api/", h.notFound)
+	h.mux.Handle("/", http.FileServer(http.Dir("./web")))
+}
+
+func (h *Handler) handle(pattern string, scope auth.Scope, handler http.HandlerFunc) {
+	h.routes = append(h.routes, route{pattern: pattern, scope: scope})
+	h.mux.HandleFunc(pattern, h.requireScope(scope, false, h.rateLimited(handler)))
+}
+
+// handleStream регистрирует потоковый маршрут, которому токен можно передать в query-строке.
+func (h *Handler) handleStream(pattern string, scope auth.Scope, handler http.HandlerFunc) {
+	h.routes = append(h.routes, route{pattern: pattern, scope: scope})
+	h.mux.HandleFunc(pattern, h.requireScope(scope, true, h.rateLimited(handler)))
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	switch r.URL.Path {
-	case "/order":
-		h.getOrder(w, r)
-	default:
-		http.FileServer(http.Dir("./web")).ServeHTTP(w, r)
+	h.logRequest(w, r, http.HandlerFunc(h.serve))
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/order" {
+		if h.applyCORS(w, r) {
+			return
+		}
 	}
+
+	// WebSocket-рукопожатию нужен исходный ResponseWriter, поэтому его не оборачиваем.
+	w.Header().Add("Vary", "Accept-Encoding")
+	if acceptsGzip(r) && r.Header.Get("Upgrade") == "" {
+		cw := newCompressWriter(w)
+		defer cw.Close()
+		w = cw
+	}
+	h.mux.ServeHTTP(w, r)
 }
 
-func (h *Handler) getOrder(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+// notFound отвечает JSON-ошибкой на неизвестные пути API.
+// Если путь существует, но с другим методом, возвращается 405 со списком допустимых методов.
+func (h *Handler) notFound(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := h.mux.Handler(probe); pattern != "/api/" {
+			allowed = append(allowed, method)
+		}
+	}
 
-	uid := r.URL.Query().Get("uid")
-	if uid == "" {
-		http.Error(w, `{"error": "Order UID is required"}`, http.StatusBadRequest)
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
+	writeError(w, http.StatusNotFound, "Resource not found")
+}
 
-	// 1. Сначала проверяем кэш
-	if order, exists := h.cacheService.Get(uid); exists {
-		if err := json.NewEncoder(w).Encode(order); err != nil {
-			http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
-		}
+func (h *Handler) getOrder(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.orderFromRequest(w, r, r.PathValue("uid"))
+	if !ok {
 		return
 	}
+	writeOrderResource(w, r, order, redact.Order(*order, auth.RoleFromContext(r.Context())))
+}
 
-	// 2. Если нет в кэше, проверяем БД
-	order, err := h.db.GetOrder(r.Context(), uid)
-	if err != nil {
-		log.Printf("Order %s not found in DB: %v", uid, err)
-		http.Error(w, `{"error": "Order not found"}`, http.StatusNotFound)
+func (h *Handler) getOrderLegacy(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.orderFromRequest(w, r, r.URL.Query().Get("uid"))
+	if !ok {
+		return
+	}
+	writeOrderResource(w, r, order, redact.Order(*order, auth.RoleFromContext(r.Context())))
+}
+
+func (h *Handler) getOrderItems(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.orderFromRequest(w, r, r.PathValue("uid"))
+	if !ok {
 		return
 	}
+	items := order.Items
+	if items == nil {
+		items = []models.Item{}
+	}
+	writeOrderResource(w, r, order, items)
+}
 
-	// 3. Добавляем найденный заказ в кэш
-	h.cacheService.Set(uid, *order)
+func (h *Handler) getOrderPayment(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.orderFromRequest(w, r, r.PathValue("uid"))
+	if !ok {
+		return
+	}
+	writeOrderResource(w, r, order, order.Payment)
+}
 
-	// 4. Возвращаем результат
-	if err := json.NewEncoder(w).Encode(order); err != nil {
-		log.Printf("Failed to encode order %s: %v", uid, err)
-		http.Error(w, `{"error": "Internal server error"}`, http.StatusInternalServerError)
+func (h *Handler) getOrderDelivery(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.orderFromRequest(w, r, r.PathValue("uid"))
+	if !ok {
 		return
 	}
+	writeOrderResource(w, r, order, redact.Delivery(order.Delivery, auth.RoleFromContext(r.Context())))
+}
+
+// orderFromRequest загружает заказ и сам пишет ответ с ошибкой, если заказ получить не удалось.
+func (h *Handler) orderFromRequest(w http.ResponseWriter, r *http.Request, uid string) (*models.Order, bool) {
+	if uid == "" {
+		writeError(w, http.StatusBadRequest, "Order UID is required")
+		return nil, false
+	}
 
+	order, err := h.orders.Get(r.Context(), uid)
+	if writeRateLimited(w, err) {
+		return nil, false
+	}
+	if errors.Is(err, db.ErrOrderNotFound) {
+		logging.FromContext(r.Context()).Info("Order not found", "order_uid", uid)
+		writeError(w, http.StatusNotFound, "Order not found")
+		return nil, false
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to get order", "order_uid", uid, "error", err)
+		writeError(w, http.StatusServiceUnavailable, "Order storage is unavailable")
+		return nil, false
+	}
+	return order, true
 }
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrder(uid string) models.Order {
+	return models.Order{
+		OrderUID:    uid,
+		TrackNumber: "WBIL" + uid,
+		DateCreated: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Delivery: models.Delivery{
+			Name:  "Test User",
+			Phone: "+79161234567",
+			City:  "Moscow",
+		},
+		Payment: models.Payment{
+			Transaction: uid,
+			Currency:    "RUB",
+			Amount:      1000,
+		},
+		Items: []models.Item{
+			{ChrtID: 1, Name: "Item", Price: 1000},
+		},
+	}
+}
+
+func newTestHandler(t *testing.T) (http.Handler, *cache.MockCache, *db.MockDatabase) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	mockCache := cache.NewMockCache(ctrl)
+	mockDB := db.NewMockDatabase(ctrl)
+	return NewHandler(mockCache, mockDB), mockCache, mockDB
+}
+
+func serve(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) errorResponse {
+	t.Helper()
+	var body errorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	return body
+}
+
+func TestHandler_GetOrderFromCache(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	order := testOrder("cached-1")
+
+	mockCache.EXPECT().Get("cached-1").Return(order, true)
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders/cached-1")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var got models.Order
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, order.OrderUID, got.OrderUID)
+	assert.Equal(t, order.TrackNumber, got.TrackNumber)
+}
+
+func TestHandler_GetOrderFallsBackToDB(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+	order := testOrder("db-1")
+
+	mockCache.EXPECT().Get("db-1").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("db-1").Return(false)
+	mockCache.EXPECT().Peek("db-1").Return(models.Order{}, false)
+	mockDB.EXPECT().GetOrder(gomock.Any(), "db-1").Return(&order, nil)
+	mockCache.EXPECT().Set("db-1", order)
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders/db-1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHandler_GetOrderNotFound(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	mockCache.EXPECT().Get("missing").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("missing").Return(false)
+	mockCache.EXPECT().Peek("missing").Return(models.Order{}, false)
+	mockDB.EXPECT().GetOrder(gomock.Any(), "missing").Return(nil, db.ErrOrderNotFound)
+	mockCache.EXPECT().MarkMissing("missing")
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders/missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "Order not found", decodeError(t, rec).Error)
+}
+
+func TestHandler_GetOrderNegativeCache(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+
+	mockCache.EXPECT().Get("bogus").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("bogus").Return(true)
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders/bogus")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandler_GetOrderDBUnavailable(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	mockCache.EXPECT().Get("o-1").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("o-1").Return(false)
+	mockCache.EXPECT().Peek("o-1").Return(models.Order{}, false)
+	mockDB.EXPECT().GetOrder(gomock.Any(), "o-1").Return(nil, assert.AnError)
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders/o-1")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "Order storage is unavailable", decodeError(t, rec).Error)
+}
+
+func TestHandler_GetOrderSubresources(t *testing.T) {
+	order := testOrder("sub-1")
+
+	tests := []struct {
+		path  string
+		check func(t *testing.T, body []byte)
+	}{
+		{
+			path: "/api/v1/orders/sub-1/items",
+			check: func(t *testing.T, body []byte) {
+				var items []models.Item
+				require.NoError(t, json.Unmarshal(body, &items))
+				assert.Len(t, items, 1)
+			},
+		},
+		{
+			path: "/api/v1/orders/sub-1/payment",
+			check: func(t *testing.T, body []byte) {
+				var payment models.Payment
+				require.NoError(t, json.Unmarshal(body, &payment))
+				assert.Equal(t, 1000, payment.Amount)
+			},
+		},
+		{
+			path: "/api/v1/orders/sub-1/delivery",
+			check: func(t *testing.T, body []byte) {
+				var delivery models.Delivery
+				require.NoError(t, json.Unmarshal(body, &delivery))
+				assert.Equal(t, "Moscow", delivery.City)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			h, mockCache, _ := newTestHandler(t)
+			mockCache.EXPECT().Get("sub-1").Return(order, true)
+
+			rec := serve(h, http.MethodGet, tt.path)
+			require.Equal(t, http.StatusOK, rec.Code)
+			tt.check(t, rec.Body.Bytes())
+		})
+	}
+}
+
+func TestHandler_LegacyOrderEndpoint(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	mockCache.EXPECT().Get("legacy-1").Return(testOrder("legacy-1"), true)
+
+	rec := serve(h, http.MethodGet, "/order?uid=legacy-1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(h, http.MethodGet, "/order")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "Order UID is required", decodeError(t, rec).Error)
+}
+
+func TestHandler_UnknownRoutes(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	rec := serve(h, http.MethodGet, "/api/v1/unknown")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "Resource not found", decodeError(t, rec).Error)
+
+	rec = serve(h, http.MethodDelete, "/api/v1/orders/some-uid")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET", rec.Header().Get("Allow"))
+}
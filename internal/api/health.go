@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"l0/internal/health"
+	"l0/internal/logging"
+)
+
+// WithReadiness задаёт проверки зависимостей для /readyz.
+// Без неё /readyz отвечает готовностью, как только процесс принимает запросы.
+func WithReadiness(readiness *health.Readiness) Option {
+	return func(h *Handler) {
+		h.readiness = readiness
+	}
+}
+
+type livenessResponse struct {
+	Status string `json:"status"`
+}
+
+// getHealthz сообщает, что процесс жив и обрабатывает HTTP-запросы. Зависимости не проверяются:
+// их недоступность не лечится перезапуском.
+func (h *Handler) getHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, livenessResponse{Status: "ok"})
+}
+
+// getReadyz отвечает 200, если все зависимости доступны, и 503 с разбором по
+// зависимостям, если нет или сервис останавливается.
+func (h *Handler) getReadyz(w http.ResponseWriter, r *http.Request) {
+	report := h.readiness.Check(r.Context())
+	h.logReadinessChange(r, report)
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, report)
+}
+
+// logReadinessChange пишет в лог смену готовности с перечнем непрошедших проверок.
+func (h *Handler) logReadinessChange(r *http.Request, report health.Report) {
+	if previous, _ := h.readyStatus.Swap(report.Status).(health.Status); previous == report.Status {
+		return
+	}
+	logger := logging.FromContext(r.Context())
+	if report.Ready() {
+		logger.Info("Service is ready")
+		return
+	}
+
+	var failed []string
+	for name, check := range report.Checks {
+		if check.Status != health.StatusUp {
+			failed = append(failed, name+": "+check.Error)
+		}
+	}
+	sort.Strings(failed)
+	logger.Warn("Service is not ready", "status", report.Status, "failed_checks", failed)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"l0/internal/health"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeReadiness(t *testing.T, body []byte) health.Report {
+	t.Helper()
+	var report health.Report
+	require.NoError(t, json.Unmarshal(body, &report))
+	return report
+}
+
+func TestHealthz(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	rec := serve(h, http.MethodGet, "/healthz")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status": "ok"}`, rec.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	readiness := health.NewReadiness()
+	postgresErr := error(nil)
+	readiness.Register("postgres", func(context.Context) (map[string]interface{}, error) {
+		return nil, postgresErr
+	})
+	warmUp := health.NewFlag("cache warm-up is in progress")
+	readiness.Register("cache", warmUp.Check)
+
+	h, _ := newAuthTestHandler(t, WithReadiness(readiness))
+
+	rec := serve(h, http.MethodGet, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	report := decodeReadiness(t, rec.Body.Bytes())
+	assert.Equal(t, health.StatusNotReady, report.Status)
+	assert.Equal(t, health.StatusUp, report.Checks["postgres"].Status)
+	assert.Equal(t, "cache warm-up is in progress", report.Checks["cache"].Error)
+
+	warmUp.Set(true, map[string]interface{}{"orders": 30})
+	rec = serve(h, http.MethodGet, "/readyz")
+	require.Equal(t, http.StatusOK, rec.Code)
+	report = decodeReadiness(t, rec.Body.Bytes())
+	assert.Equal(t, health.StatusReady, report.Status)
+	assert.Equal(t, float64(30), report.Checks["cache"].Details["orders"])
+
+	postgresErr = errors.New("connection refused")
+	rec = serve(h, http.MethodGet, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "connection refused", decodeReadiness(t, rec.Body.Bytes()).Checks["postgres"].Error)
+}
+
+func TestReadyz_Draining(t *testing.T) {
+	readiness := health.NewReadiness()
+	h := NewHandler(nil, nil, WithReadiness(readiness))
+
+	require.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/readyz").Code)
+
+	readiness.Drain()
+	rec := serve(h, http.MethodGet, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, health.StatusDraining, decodeReadiness(t, rec.Body.Bytes()).Status)
+
+	// Процесс при этом жив и продолжает обслуживать запросы.
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/healthz").Code)
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyMaxEntries ограничивает память хранилища: при переполнении
+	// забываются самые старые ключи с готовым ответом.
+	idempotencyMaxEntries = 10000
+)
+
+type idempotencyState int
+
+const (
+	idempotencyNew idempotencyState = iota
+	idempotencyInFlight
+	idempotencyReplay
+	idempotencyMismatch
+	// idempotencyFull - хранилище заполнено запросами, которые ещё выполняются.
+	idempotencyFull
+)
+
+type storedResponse struct {
+	status int
+	body   []byte
+}
+
+type idempotencyEntry struct {
+	key         string
+	requestHash [sha256.Size]byte
+	response    *storedResponse
+	expiresAt   time.Time
+}
+
+// idempotencyStore запоминает ответы на запросы с заголовком Idempotency-Key,
+// чтобы повторная отправка того же запроса не создавала заказы повторно.
+// Ключи хранятся в порядке добавления: при общем TTL это и порядок истечения,
+// поэтому истёкшие записи снимаются с начала списка без обхода всего хранилища.
+type idempotencyStore struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+}
+
+func newIdempotencyStore(ttl time.Duration, maxEntries int) *idempotencyStore {
+	return &idempotencyStore{
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// begin резервирует ключ за запросом. Ключ должен включать клиента (см. idempotencyKey),
+// иначе один клиент мог бы получить сохранённый ответ другого. Для уже выполненного запроса возвращает
+// сохранённый ответ, для того же ключа с другим телом - idempotencyMismatch.
+func (s *idempotencyStore) begin(key string, body []byte) (idempotencyState, *storedResponse) {
+	hash := sha256.Sum256(body)
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired(now)
+
+	elem, ok := s.entries[key]
+	if !ok {
+		if s.maxEntries > 0 && s.order.Len() >= s.maxEntries && !s.evictCompleted() {
+			return idempotencyFull, nil
+		}
+		entry := &idempotencyEntry{key: key, requestHash: hash, expiresAt: now.Add(s.ttl)}
+		s.entries[key] = s.order.PushBack(entry)
+		return idempotencyNew, nil
+	}
+	entry := elem.Value.(*idempotencyEntry)
+	if entry.requestHash != hash {
+		return idempotencyMismatch, nil
+	}
+	if entry.response == nil {
+		return idempotencyInFlight, nil
+	}
+	return idempotencyReplay, entry.response
+}
+
+// finish сохраняет ответ. Ответы 5xx не запоминаются, чтобы клиент мог повторить запрос.
+func (s *idempotencyStore) finish(key string, status int, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	if status >= 500 {
+		s.remove(elem)
+		return
+	}
+	elem.Value.(*idempotencyEntry).response = &storedResponse{status: status, body: body}
+}
+
+func (s *idempotencyStore) evictExpired(now time.Time) {
+	for elem := s.order.Front(); elem != nil; elem = s.order.Front() {
+		if !now.After(elem.Value.(*idempotencyEntry).expiresAt) {
+			return
+		}
+		s.remove(elem)
+	}
+}
+
+// evictCompleted забывает самый старый ключ с готовым ответом. Выполняющиеся запросы
+// не вытесняются: иначе их повтор с тем же ключом выполнился бы второй раз. Обход
+// пропускает только выполняющиеся запросы, а их не больше, чем одновременных запросов.
+func (s *idempotencyStore) evictCompleted() bool {
+	for elem := s.order.Front(); elem != nil; elem = elem.Next() {
+		if elem.Value.(*idempotencyEntry).response != nil {
+			s.remove(elem)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *idempotencyStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.entries, elem.Value.(*idempotencyEntry).key)
+}
+
+// idempotencyKey привязывает Idempotency-Key к клиенту: одинаковые ключи разных
+// клиентов не пересекаются.
+func idempotencyKey(r *http.Request, key string) string {
+	return clientKey(r) + "\x00" + key
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"l0/internal/domain"
+	"l0/internal/events"
+	"l0/internal/logging"
+	"l0/internal/models"
+	"l0/internal/utils"
+)
+
+const (
+	maxIngestBodyBytes = 8 << 20
+	maxIngestBatchSize = 500
+
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// OrderPublisher отправляет заказы в Kafka вместо прямой записи в БД.
+type OrderPublisher interface {
+	PublishOrders(ctx context.Context, orders ...models.Order) error
+}
+
+type ingestResult struct {
+	OrderUID string `json:"order_uid"`
+	Status   string `json:"status"`
+}
+
+type ingestResponse struct {
+	Orders []ingestResult `json:"orders"`
+}
+
+// createOrders принимает один заказ или массив заказов, валидирует их так же,
+// как консьюмер, и публикует в Kafka (202) либо сохраняет в БД (201).
+func (h *Handler) createOrders(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIngestBodyBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "Request body is too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		status, response := h.ingest(r.Context(), body)
+		writeJSON(w, status, response)
+		return
+	}
+
+	key = idempotencyKey(r, key)
+	state, stored := h.idempotency.begin(key, body)
+	switch state {
+	case idempotencyReplay:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(stored.status)
+		_, _ = w.Write(stored.body)
+		return
+	case idempotencyInFlight:
+		writeError(w, http.StatusConflict, "A request with this Idempotency-Key is already in progress")
+		return
+	case idempotencyMismatch:
+		writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request body")
+		return
+	case idempotencyFull:
+		writeError(w, http.StatusServiceUnavailable, "Too many requests with Idempotency-Key are in progress, retry later")
+		return
+	}
+
+	status, response := h.ingest(r.Context(), body)
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		h.idempotency.finish(key, http.StatusInternalServerError, nil)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	h.idempotency.finish(key, status, encoded)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(encoded, '\n'))
+}
+
+func (h *Handler) ingest(ctx context.Context, body []byte) (int, interface{}) {
+	orders, batch, err := decodeOrders(body)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse{Error: err.Error()}
+	}
+
+	var validationErrors []utils.ValidationError
+	now := time.Now().UTC()
+	for i := range orders {
+		order := orders[i]
+		if err := utils.ValidateStruct(order); err != nil {
+			errs := utils.GetValidationErrors(err)
+			if batch {
+				errs = utils.PrefixPaths("["+strconv.Itoa(i)+"]", errs)
+			}
+			validationErrors = append(validationErrors, errs...)
+		}
+		domain.InitStatus(&orders[i], now)
+	}
+	if len(validationErrors) > 0 {
+		return http.StatusUnprocessableEntity, errorResponse{Error: "Validation failed", Details: validationErrors}
+	}
+
+	if h.publisher != nil {
+		if err := h.publisher.PublishOrders(ctx, orders...); err != nil {
+			logging.FromContext(ctx).Error("Failed to publish orders", "count", len(orders), "error", err)
+			return http.StatusServiceUnavailable, errorResponse{Error: "Failed to accept orders"}
+		}
+		return http.StatusAccepted, newIngestResponse(orders, "accepted")
+	}
+
+	// Пачка сохраняется одной транзакцией: после сбоя в БД не остаётся её части,
+	// и повтор запроса с тем же Idempotency-Key сохраняет её целиком.
+	created, err := h.db.SaveOrders(ctx, orders)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to save orders", "count", len(orders), "error", err)
+		return http.StatusInternalServerError, errorResponse{Error: "Failed to save orders"}
+	}
+	resp := newIngestResponse(orders, "created")
+	for i, order := range orders {
+		if !created[i] {
+			// Заказ уже сохранён раньше, его статус в кэше мог измениться.
+			resp.Orders[i].Status = "exists"
+			continue
+		}
+		h.cacheService.Set(order.OrderUID, order)
+		h.events.Publish(events.OrderCreated, order)
+	}
+	return http.StatusCreated, resp
+}
+
+// decodeOrders разбирает тело как одиночный заказ или как массив заказов.
+func decodeOrders(body []byte) ([]models.Order, bool, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, false, errors.New("request body is empty")
+	}
+
+	if trimmed[0] != '[' {
+		var order models.Order
+		if err := json.Unmarshal(trimmed, &order); err != nil {
+			return nil, false, fmt.Errorf("invalid order JSON: %v", err)
+		}
+		return []models.Order{order}, false, nil
+	}
+
+	var orders []models.Order
+	if err := json.Unmarshal(trimmed, &orders); err != nil {
+		return nil, true, fmt.Errorf("invalid order JSON: %v", err)
+	}
+	if len(orders) == 0 {
+		return nil, true, errors.New("batch must contain at least one order")
+	}
+	if len(orders) > maxIngestBatchSize {
+		return nil, true, fmt.Errorf("batch must contain at most %d orders", maxIngestBatchSize)
+	}
+	return orders, true, nil
+}
+
+func newIngestResponse(orders []models.Order, status string) ingestResponse {
+	results := make([]ingestResult, len(orders))
+	for i, order := range orders {
+		results[i] = ingestResult{OrderUID: order.OrderUID, Status: status}
+	}
+	return ingestResponse{Orders: results}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"l0/internal/auth"
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/models"
+	"l0/internal/utils"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	published []models.Order
+	err       error
+}
+
+func (p *recordingPublisher) PublishOrders(_ context.Context, orders ...models.Order) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, orders...)
+	return nil
+}
+
+func validOrderJSON(uid string) string {
+	order := map[string]interface{}{
+		"order_uid":    uid,
+		"track_number": "WBILMTESTTRACK",
+		"entry":        "WBIL",
+		"delivery": map[string]interface{}{
+			"name": "Test Testov", "phone": "+9720000000", "zip": "2639809", "city": "Kiryat Mozkin",
+			"address": "Ploshad Mira 15", "region": "Kraiot", "email": "test@gmail.com",
+		},
+		"payment": map[string]interface{}{
+			"transaction": uid, "request_id": "", "currency": "USD", "provider": "wbpay", "amount": 1817,
+			"payment_dt": 1637907727, "bank": "alpha", "delivery_cost": 1500, "goods_total": 317, "custom_fee": 0,
+		},
+		"items": []interface{}{
+			map[string]interface{}{
+				"chrt_id": 9934930, "track_number": "WBILMTESTTRACK", "price": 453, "rid": "ab4219087a764ae0btest",
+				"name": "Mascaras", "sale": 30, "size": "0", "total_price": 317, "nm_id": 2389212,
+				"brand": "Vivienne Sabo", "status": 202,
+			},
+		},
+		"locale": "en", "internal_signature": "", "customer_id": "test", "delivery_service": "meest",
+		"shardkey": "9", "sm_id": 99, "date_created": "2021-11-26T06:22:19Z", "oof_shard": "1",
+	}
+	data, _ := json.Marshal(order)
+	return string(data)
+}
+
+func post(h http.Handler, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_CreateOrderDirect(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	mockDB.EXPECT().SaveOrders(gomock.Any(), gomock.Any()).Return([]bool{true}, nil)
+	mockCache.EXPECT().Set("direct-1", gomock.Any())
+
+	rec := post(h, validOrderJSON("direct-1"), nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var body ingestResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, []ingestResult{{OrderUID: "direct-1", Status: "created"}}, body.Orders)
+}
+
+func TestHandler_CreateOrderDirectDuplicate(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+
+	// Заказ уже есть в БД: кэш не перезаписывается (Set не ожидается).
+	mockDB.EXPECT().SaveOrders(gomock.Any(), gomock.Any()).Return([]bool{false}, nil)
+
+	rec := post(h, validOrderJSON("dup-1"), nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var body ingestResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, []ingestResult{{OrderUID: "dup-1", Status: "exists"}}, body.Orders)
+}
+
+func TestHandler_CreateOrdersDirectBatchFailure(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+
+	mockDB.EXPECT().SaveOrders(gomock.Any(), gomock.Len(2)).Return(nil, assert.AnError).Times(2)
+
+	headers := map[string]string{idempotencyKeyHeader: "batch-key"}
+	body := "[" + validOrderJSON("tx-1") + "," + validOrderJSON("tx-2") + "]"
+	assert.Equal(t, http.StatusInternalServerError, post(h, body, headers).Code)
+	assert.Equal(t, http.StatusInternalServerError, post(h, body, headers).Code, "failed batch can be retried")
+}
+
+func TestHandler_CreateOrdersPublishesBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	publisher := &recordingPublisher{}
+	h := NewHandler(cache.NewMockCache(ctrl), db.NewMockDatabase(ctrl), WithPublisher(publisher))
+
+	rec := post(h, "["+validOrderJSON("batch-1")+","+validOrderJSON("batch-2")+"]", nil)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Len(t, publisher.published, 2)
+	assert.Equal(t, "batch-2", publisher.published[1].OrderUID)
+}
+
+func TestHandler_CreateOrdersValidationErrors(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	invalid := strings.Replace(validOrderJSON("bad-1"), `"price":453`, `"price":-1`, 1)
+	rec := post(h, "["+validOrderJSON("ok-1")+","+invalid+"]", nil)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	var body struct {
+		Error   string                  `json:"error"`
+		Details []utils.ValidationError `json:"details"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Len(t, body.Details, 1)
+	assert.Equal(t, "[1].items[0].price", body.Details[0].Path)
+	assert.Equal(t, "min", body.Details[0].Rule)
+}
+
+func TestHandler_CreateOrdersBadJSON(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	assert.Equal(t, http.StatusBadRequest, post(h, "{not json", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, post(h, "[]", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, post(h, "", nil).Code)
+}
+
+func TestHandler_CreateOrdersIdempotency(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	mockDB.EXPECT().SaveOrders(gomock.Any(), gomock.Any()).Return([]bool{true}, nil).Times(1)
+	mockCache.EXPECT().Set("idem-1", gomock.Any()).Times(1)
+
+	headers := map[string]string{idempotencyKeyHeader: "key-1"}
+	first := post(h, validOrderJSON("idem-1"), headers)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	replay := post(h, validOrderJSON("idem-1"), headers)
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+	assert.JSONEq(t, first.Body.String(), replay.Body.String())
+
+	mismatch := post(h, validOrderJSON("idem-2"), headers)
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.Code)
+}
+
+func TestIdempotencyStore(t *testing.T) {
+	store := newIdempotencyStore(time.Minute, 2)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	state, _ := store.begin("k", []byte("body"))
+	assert.Equal(t, idempotencyNew, state)
+
+	state, _ = store.begin("k", []byte("body"))
+	assert.Equal(t, idempotencyInFlight, state)
+
+	store.finish("k", http.StatusServiceUnavailable, nil)
+	state, _ = store.begin("k", []byte("body"))
+	assert.Equal(t, idempotencyNew, state, "server errors must not be remembered")
+
+	store.finish("k", http.StatusCreated, []byte("ok"))
+	state, stored := store.begin("k", []byte("body"))
+	assert.Equal(t, idempotencyReplay, state)
+	assert.Equal(t, []byte("ok"), stored.body)
+
+	now = now.Add(2 * time.Minute)
+	state, _ = store.begin("k", []byte("body"))
+	assert.Equal(t, idempotencyNew, state, "expired keys are forgotten")
+
+	store.finish("k", http.StatusCreated, []byte("ok"))
+	store.begin("k2", []byte("body"))
+	state, _ = store.begin("k3", []byte("body"))
+	assert.Equal(t, idempotencyNew, state, "oldest completed key is dropped when the store is full")
+	assert.Len(t, store.entries, 2)
+
+	state, _ = store.begin("k", []byte("body"))
+	assert.Equal(t, idempotencyFull, state, "requests in progress are not evicted")
+	state, _ = store.begin("k2", []byte("body"))
+	assert.Equal(t, idempotencyInFlight, state)
+
+	store.finish("k2", http.StatusCreated, []byte("ok"))
+	state, _ = store.begin("k", []byte("body"))
+	assert.Equal(t, idempotencyNew, state)
+}
+
+func TestIdempotencyKey_ScopedByClient(t *testing.T) {
+	request := func(subject string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/orders", nil)
+		return r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Subject: subject}))
+	}
+
+	assert.Equal(t, idempotencyKey(request("crm"), "k"), idempotencyKey(request("crm"), "k"))
+	assert.NotEqual(t, idempotencyKey(request("crm"), "k"), idempotencyKey(request("shop"), "k"),
+		"one client cannot replay another client's response")
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"l0/internal/auth"
+	"l0/internal/db"
+	"l0/internal/logging"
+	"l0/internal/models"
+	"l0/internal/redact"
+)
+
+type orderListResponse struct {
+	Orders     []models.Order `json:"orders"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func (h *Handler) listOrders(w http.ResponseWriter, r *http.Request) {
+	params, err := parseListParams(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.orders.List(r.Context(), params)
+	if writeRateLimited(w, err) {
+		return
+	}
+	if errors.Is(err, db.ErrInvalidCursor) {
+		writeError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to list orders", "error", err)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	orders := redact.Orders(page.Orders, auth.RoleFromContext(r.Context()))
+	if orders == nil {
+		orders = []models.Order{}
+	}
+	writeJSON(w, http.StatusOK, orderListResponse{Orders: orders, NextCursor: page.NextCursor})
+}
+
+// parseListParams разбирает фильтры, сортировку и пагинацию из query-строки.
+// Сортировка задаётся как sort=date|amount, префикс "-" означает убывание.
+func parseListParams(query url.Values) (db.ListOrdersParams, error) {
+	params := db.ListOrdersParams{
+		Filter: db.OrderFilter{
+			CustomerID:      query.Get("customer_id"),
+			TrackNumber:     query.Get("track_number"),
+			DeliveryService: query.Get("delivery_service"),
+			PaymentProvider: query.Get("provider"),
+			Currency:        query.Get("currency"),
+			ItemBrand:       query.Get("brand"),
+		},
+		Cursor: query.Get("cursor"),
+	}
+
+	var err error
+	if params.Sort, params.Desc, err = db.ParseSort(query.Get("sort")); err != nil {
+		return params, err
+	}
+	if params.Filter.CreatedFrom, err = parseTimeParam(query, "created_from"); err != nil {
+		return params, err
+	}
+	if params.Filter.CreatedTo, err = parseTimeParam(query, "created_to"); err != nil {
+		return params, err
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > db.MaxListLimit {
+			return params, fmt.Errorf("limit must be between 1 and %d", db.MaxListLimit)
+		}
+		params.Limit = n
+	}
+
+	return params, nil
+}
+
+func parseTimeParam(query url.Values, name string) (time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return t, nil
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"l0/internal/db"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListParams(t *testing.T) {
+	query, _ := url.ParseQuery("customer_id=c1&brand=Nike&provider=wbpay&currency=RUB" +
+		"&created_from=2024-01-01T00:00:00Z&sort=amount&limit=50&cursor=abc")
+
+	params, err := parseListParams(query)
+	require.NoError(t, err)
+
+	assert.Equal(t, "c1", params.Filter.CustomerID)
+	assert.Equal(t, "Nike", params.Filter.ItemBrand)
+	assert.Equal(t, "wbpay", params.Filter.PaymentProvider)
+	assert.Equal(t, "RUB", params.Filter.Currency)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), params.Filter.CreatedFrom)
+	assert.Equal(t, db.SortByAmount, params.Sort)
+	assert.False(t, params.Desc)
+	assert.Equal(t, 50, params.Limit)
+	assert.Equal(t, "abc", params.Cursor)
+}
+
+func TestParseListParams_Defaults(t *testing.T) {
+	params, err := parseListParams(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, db.SortByDate, params.Sort)
+	assert.True(t, params.Desc)
+	assert.Zero(t, params.Limit)
+}
+
+func TestParseListParams_Invalid(t *testing.T) {
+	for _, raw := range []string{"sort=name", "limit=0", "limit=1000", "created_to=yesterday"} {
+		query, _ := url.ParseQuery(raw)
+		_, err := parseListParams(query)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestHandler_ListOrders(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+
+	mockDB.EXPECT().
+		ListOrders(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, params db.ListOrdersParams) (*db.OrderPage, error) {
+			assert.Equal(t, "meest", params.Filter.DeliveryService)
+			return &db.OrderPage{Orders: []models.Order{testOrder("a"), testOrder("b")}, NextCursor: "next"}, nil
+		})
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders?delivery_service=meest")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body orderListResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Len(t, body.Orders, 2)
+	assert.Equal(t, "next", body.NextCursor)
+}
+
+func TestHandler_ListOrdersInvalidCursor(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+	mockDB.EXPECT().ListOrders(gomock.Any(), gomock.Any()).Return(nil, db.ErrInvalidCursor)
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders?cursor=broken")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"l0/internal/auth"
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/logging"
+	"l0/internal/models"
+	"l0/internal/ratelimit"
+	"l0/internal/redact"
+)
+
+type orderLookupResponse struct {
+	Orders []models.Order `json:"orders"`
+	// Truncated - найдено больше db.MaxLookupResults заказов; остальные доступны
+	// через /api/v1/orders с фильтрами track_number или customer_id.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+func (h *Handler) getOrdersByTrackNumber(w http.ResponseWriter, r *http.Request) {
+	h.lookupOrders(w, r, cache.ByTrackNumber, r.PathValue("track"), h.db.FindOrdersByTrackNumber)
+}
+
+func (h *Handler) getOrdersByCustomer(w http.ResponseWriter, r *http.Request) {
+	h.lookupOrders(w, r, cache.ByCustomer, r.PathValue("customerID"), h.db.FindOrdersByCustomer)
+}
+
+// lookupOrders отвечает из вторичного индекса кэша, а при неполных данных
+// загружает из БД до db.MaxLookupResults заказов. В кэш сохраняется только полный
+// результат: индекс считает сохранённый набор всеми заказами по ключу.
+func (h *Handler) lookupOrders(
+	w http.ResponseWriter,
+	r *http.Request,
+	kind cache.IndexKind,
+	key string,
+	find func(ctx context.Context, key string, limit int) ([]models.Order, error),
+) {
+	orders, ok := h.cacheService.Lookup(kind, key)
+	logging.RecordCacheLookup(r.Context(), ok)
+	budget := ratelimit.BudgetCache
+	if !ok {
+		budget = ratelimit.BudgetDB
+	}
+	if writeRateLimited(w, ratelimit.Charge(r.Context(), budget)) {
+		return
+	}
+
+	if !ok {
+		var err error
+		orders, err = find(r.Context(), key, db.MaxLookupResults+1)
+		if err != nil {
+			logging.FromContext(r.Context()).Error("Failed to look up orders", "key", key, "error", err)
+			writeError(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if len(orders) <= db.MaxLookupResults {
+			h.cacheService.StoreLookup(kind, key, orders)
+		}
+	}
+
+	truncated := len(orders) > db.MaxLookupResults
+	if truncated {
+		orders = orders[:db.MaxLookupResults]
+	}
+
+	orders = redact.Orders(orders, auth.RoleFromContext(r.Context()))
+	if orders == nil {
+		orders = []models.Order{}
+	}
+	writeJSON(w, http.StatusOK, orderLookupResponse{Orders: orders, Truncated: truncated})
+}
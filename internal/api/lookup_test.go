@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_OrdersByTrackNumberFromCache(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+
+	mockCache.EXPECT().
+		Lookup(cache.ByTrackNumber, "WBIL-1").
+		Return([]models.Order{testOrder("t-1")}, true)
+
+	rec := serve(h, http.MethodGet, "/api/v1/tracks/WBIL-1/orders")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body orderLookupResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Len(t, body.Orders, 1)
+	assert.Equal(t, "t-1", body.Orders[0].OrderUID)
+}
+
+func TestHandler_OrdersByCustomerFallsBackToDB(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+	orders := []models.Order{testOrder("c-1"), testOrder("c-2")}
+
+	mockCache.EXPECT().Lookup(cache.ByCustomer, "cust-1").Return(nil, false)
+	mockDB.EXPECT().FindOrdersByCustomer(gomock.Any(), "cust-1", db.MaxLookupResults+1).Return(orders, nil)
+	mockCache.EXPECT().StoreLookup(cache.ByCustomer, "cust-1", orders)
+
+	rec := serve(h, http.MethodGet, "/api/v1/customers/cust-1/orders")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body orderLookupResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Len(t, body.Orders, 2)
+	assert.False(t, body.Truncated)
+}
+
+func TestHandler_OrdersByCustomerTruncated(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+	orders := make([]models.Order, db.MaxLookupResults+1)
+	for i := range orders {
+		orders[i] = testOrder(fmt.Sprintf("c-%d", i))
+	}
+
+	// Неполный набор не сохраняется в кэш: StoreLookup не ожидается.
+	mockCache.EXPECT().Lookup(cache.ByCustomer, "cust-2").Return(nil, false)
+	mockDB.EXPECT().FindOrdersByCustomer(gomock.Any(), "cust-2", db.MaxLookupResults+1).Return(orders, nil)
+
+	rec := serve(h, http.MethodGet, "/api/v1/customers/cust-2/orders")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body orderLookupResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Len(t, body.Orders, db.MaxLookupResults)
+	assert.True(t, body.Truncated)
+}
+
+func TestHandler_OrdersByTrackNumberDBError(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	mockCache.EXPECT().Lookup(cache.ByTrackNumber, "WBIL-2").Return(nil, false)
+	mockDB.EXPECT().FindOrdersByTrackNumber(gomock.Any(), "WBIL-2", gomock.Any()).Return(nil, assert.AnError)
+
+	rec := serve(h, http.MethodGet, "/api/v1/tracks/WBIL-2/orders")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,590 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/health"
+	"l0/internal/logging"
+	"l0/internal/models"
+	"l0/internal/service"
+	"l0/internal/utils"
+)
+
+type openAPIDocument struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       openAPIInfo                      `json:"info"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components openAPIComponents                `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*schema        `json:"schemas"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+type operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	// Scopes - scope, необходимый для вызова (расширение OpenAPI).
+	Scopes []string `json:"x-required-scopes,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Headers     map[string]header    `json:"headers,omitempty"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+func pathParam(name, description string) parameter {
+	return parameter{Name: name, In: "path", Description: description, Required: true, Schema: &schema{Type: "string"}}
+}
+
+func queryParam(name, description string, s *schema) parameter {
+	return parameter{Name: name, In: "query", Description: description, Schema: s}
+}
+
+func stringSchema() *schema {
+	return &schema{Type: "string"}
+}
+
+// sortValues перечисляет допустимые значения sort: поля db.SortFields и они же с "-".
+func sortValues() []string {
+	values := make([]string, 0, 2*len(db.SortFields))
+	for _, field := range db.SortFields {
+		values = append(values, string(field), "-"+string(field))
+	}
+	return values
+}
+
+func jsonContent(s *schema) map[string]mediaType {
+	return map[string]mediaType{"application/json": {Schema: s}}
+}
+
+func jsonResponse(description string, s *schema) *response {
+	return &response{Description: description, Content: jsonContent(s)}
+}
+
+func errorResponseOf(description string) *response {
+	return jsonResponse(description, componentRef("Error"))
+}
+
+// openAPIDoc кодирует описание один раз: маршруты после создания обработчика не меняются.
+type openAPIDoc struct {
+	once sync.Once
+	data []byte
+}
+
+func (h *Handler) getOpenAPI(w http.ResponseWriter, r *http.Request) {
+	h.openAPI.once.Do(func() {
+		var err error
+		if h.openAPI.data, err = json.Marshal(buildOpenAPI(h.routes)); err != nil {
+			logging.FromContext(r.Context()).Error("Failed to encode OpenAPI document", "error", err)
+		}
+	})
+	if h.openAPI.data == nil {
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(h.openAPI.data)
+}
+
+// buildOpenAPI описывает все маршруты обработчика. Схемы тел строятся по тем же
+// Go-типам, что кодируются в ответах, а требования к доступу - по scope маршрутов.
+func buildOpenAPI(routes []route) *openAPIDocument {
+	reg := newSchemaRegistry()
+
+	reg.define("Delivery", models.Delivery{})
+	reg.define("Payment", models.Payment{})
+	reg.define("Item", models.Item{})
+	order := reg.define("Order", models.Order{})
+	reg.schemas["Order"].Properties["status"].Enum = statusNames()
+	reg.define("StatusChange", models.StatusChange{})
+	reg.define("ValidationError", utils.ValidationError{})
+	reg.define("Error", errorResponse{})
+	reg.schemas["Error"].Properties["details"].Description = "Подробности ошибки; для 422 - список ValidationError"
+
+	orderList := reg.define("OrderList", orderListResponse{})
+	lookup := reg.define("OrderLookup", orderLookupResponse{})
+	ingest := reg.define("IngestResponse", ingestResponse{})
+	batchRequest := reg.define("BatchGetRequest", batchGetRequest{})
+	reg.schemas["BatchGetRequest"].Required = []string{"order_uids"}
+	reg.schemas["BatchGetRequest"].Properties["order_uids"].MaxItems = intPtr(service.MaxBatchGet)
+	batchResponse := reg.define("BatchGetResponse", batchGetResponse{})
+	statusUpdate := reg.define("StatusUpdateRequest", statusUpdateRequest{})
+	reg.schemas["StatusUpdateRequest"].Properties["status"].Enum = statusNames()
+	reg.schemas["StatusUpdateRequest"].Required = []string{"status"}
+	status := reg.define("OrderStatus", statusResponse{})
+	rateLimits := reg.define("RateLimits", rateLimitsResponse{})
+	cacheWarmup := reg.define("CacheWarmup", cacheWarmupResponse{})
+	reg.define("CacheStats", cache.Stats{})
+	cacheState := reg.define("CacheState", cacheStatsResponse{})
+	cacheFlush := reg.define("CacheFlush", cacheFlushResponse{})
+	liveness := reg.define("Liveness", livenessResponse{})
+	reg.define("CheckResult", health.CheckResult{})
+	reg.schemas["CheckResult"].Properties["status"].Enum = []string{string(health.StatusUp), string(health.StatusDown)}
+	readiness := reg.define("Readiness", health.Report{})
+	reg.schemas["Readiness"].Properties["status"].Enum = []string{
+		string(health.StatusReady), string(health.StatusNotReady), string(health.StatusDraining),
+	}
+	reg.schemas["Readiness"].Properties["checks"].Description = "Результаты проверок по именам зависимостей (CheckResult)"
+	reg.schemas["OrderStatus"].Properties["status"].Enum = statusNames()
+
+	uid := pathParam("uid", "Идентификатор заказа (order_uid)")
+	notFound := errorResponseOf("Заказ не найден")
+	internal := errorResponseOf("Внутренняя ошибка")
+	unavailable := errorResponseOf("Хранилище заказов недоступно")
+	badRequest := errorResponseOf("Некорректные параметры запроса")
+	tooMany := &response{
+		Description: "Исчерпан бюджет запросов клиента (из кэша или к БД)",
+		Headers: map[string]header{
+			"Retry-After": {Description: "Через сколько секунд повторить запрос", Schema: &schema{Type: "integer"}},
+		},
+		Content: jsonContent(componentRef("Error")),
+	}
+
+	orderResource := func(id, summary string, body *schema) *operation {
+		return &operation{
+			OperationID: id,
+			Summary:     summary,
+			Tags:        []string{"orders"},
+			Parameters:  []parameter{uid, ifNoneMatchParam(), ifModifiedSinceParam()},
+			Responses: map[string]*response{
+				"200": {
+					Description: "OK",
+					Headers: map[string]header{
+						"ETag":          {Description: "Слабый хэш содержимого ответа (W/\"...\")", Schema: stringSchema()},
+						"Last-Modified": {Description: "Время последнего изменения заказа", Schema: stringSchema()},
+					},
+					Content: jsonContent(body),
+				},
+				"304": {Description: "Клиентская копия актуальна"},
+				"400": badRequest,
+				"404": notFound,
+				"429": tooMany,
+				"503": unavailable,
+			},
+		}
+	}
+
+	filters := []parameter{
+		queryParam("customer_id", "Покупатель", stringSchema()),
+		queryParam("track_number", "Трек-номер заказа", stringSchema()),
+		queryParam("delivery_service", "Служба доставки", stringSchema()),
+		queryParam("created_from", "Начало периода date_created (RFC3339, включительно)", &schema{Type: "string", Format: "date-time"}),
+		queryParam("created_to", "Конец периода date_created (RFC3339, не включая)", &schema{Type: "string", Format: "date-time"}),
+		queryParam("provider", "Платёжный провайдер", stringSchema()),
+		queryParam("currency", "Валюта оплаты", stringSchema()),
+		queryParam("brand", "Бренд любого из товаров", stringSchema()),
+		queryParam("sort", "Сортировка; префикс - означает убывание", &schema{Type: "string", Enum: sortValues()}),
+	}
+	listParams := append(append([]parameter{}, filters...),
+		queryParam("limit", "Размер страницы", &schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(db.MaxListLimit)}),
+		queryParam("cursor", "Курсор из next_cursor предыдущей страницы", stringSchema()),
+	)
+	exportParams := append(append([]parameter{}, filters...),
+		queryParam("format", "Формат выгрузки", &schema{Type: "string", Enum: []string{"csv", "ndjson", "xlsx"}}),
+		queryParam("limit", "Максимальное число заказов", &schema{Type: "integer", Minimum: floatPtr(1)}),
+	)
+
+	paths := map[string]map[string]*operation{
+		"/api/openapi.json": {
+			"get": {
+				OperationID: "getOpenAPI",
+				Summary:     "Это описание API",
+				Tags:        []string{"meta"},
+				Responses: map[string]*response{
+					"200": jsonResponse("Документ OpenAPI 3", &schema{Type: "object"}),
+				},
+			},
+		},
+		apiPrefix + "/orders": {
+			"get": {
+				OperationID: "listOrders",
+				Summary:     "Список заказов с фильтрами и курсорной пагинацией",
+				Tags:        []string{"orders"},
+				Parameters:  listParams,
+				Responses: map[string]*response{
+					"200": jsonResponse("Страница заказов", orderList),
+					"400": badRequest,
+					"429": tooMany,
+					"500": internal,
+				},
+			},
+			"post": {
+				OperationID: "createOrders",
+				Summary:     "Приём одного заказа или массива заказов",
+				Description: "Заказы проверяются теми же правилами, что и в консьюмере Kafka.",
+				Tags:        []string{"ingestion"},
+				Parameters: []parameter{{
+					Name: idempotencyKeyHeader, In: "header", Schema: stringSchema(),
+					Description: "Повтор запроса с тем же ключом возвращает сохранённый ответ",
+				}},
+				RequestBody: &requestBody{
+					Required: true,
+					Content: jsonContent(&schema{OneOf: []*schema{
+						order,
+						{Type: "array", Items: order, MinItems: intPtr(1), MaxItems: intPtr(maxIngestBatchSize)},
+					}}),
+				},
+				Responses: map[string]*response{
+					"201": jsonResponse("Заказы сохранены в БД", ingest),
+					"202": jsonResponse("Заказы опубликованы в Kafka", ingest),
+					"400": badRequest,
+					"409": errorResponseOf("Запрос с этим Idempotency-Key ещё обрабатывается"),
+					"413": errorResponseOf("Слишком большое тело запроса"),
+					"422": errorResponseOf("Ошибки валидации; details - список ValidationError"),
+					"500": internal,
+					"503": errorResponseOf("Kafka недоступна или выполняется слишком много запросов с Idempotency-Key"),
+				},
+			},
+		},
+		apiPrefix + "/orders:batchGet": {
+			"post": {
+				OperationID: "batchGetOrders",
+				Summary:     "Несколько заказов за один запрос",
+				Tags:        []string{"orders"},
+				RequestBody: &requestBody{Required: true, Content: jsonContent(batchRequest)},
+				Responses: map[string]*response{
+					"200": jsonResponse("Найденные заказы и список ненайденных UID", batchResponse),
+					"400": badRequest,
+					"429": tooMany,
+					"500": internal,
+				},
+			},
+		},
+		apiPrefix + "/orders/export": {
+			"get": {
+				OperationID: "exportOrders",
+				Summary:     "Выгрузка заказов в CSV, NDJSON или XLSX",
+				Tags:        []string{"orders"},
+				Parameters:  exportParams,
+				Responses: map[string]*response{
+					"200": {
+						Description: "Файл выгрузки",
+						Content: map[string]mediaType{
+							"text/csv":             {Schema: stringSchema()},
+							"application/x-ndjson": {Schema: stringSchema()},
+							"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": {
+								Schema: &schema{Type: "string", Format: "binary"},
+							},
+						},
+					},
+					"400": badRequest,
+					"429": tooMany,
+					"500": internal,
+				},
+			},
+		},
+		apiPrefix + "/orders/stream": {
+			"get": {
+				OperationID: "streamOrders",
+				Summary:     "Поток новых заказов и смен статуса (Server-Sent Events)",
+				Tags:        []string{"events"},
+				Parameters: []parameter{
+					queryParam("customer_id", "Только заказы покупателя", stringSchema()),
+					queryParam("delivery_service", "Только заказы службы доставки", stringSchema()),
+					queryParam("last_event_id", "Альтернатива заголовку Last-Event-ID", &schema{Type: "integer", Format: "int64"}),
+					{Name: "Last-Event-ID", In: "header", Description: "Продолжить поток после этого события", Schema: &schema{Type: "integer", Format: "int64"}},
+				},
+				Responses: map[string]*response{
+					"200": {
+						Description: "События order.created и order.status_changed, событие gap при потере событий",
+						Content:     map[string]mediaType{"text/event-stream": {Schema: stringSchema()}},
+					},
+					"400": badRequest,
+				},
+			},
+		},
+		apiPrefix + "/orders/ws": {
+			"get": {
+				OperationID: "subscribeOrders",
+				Summary:     "WebSocket-подписка на изменения заказов",
+				Description: `Команды клиента: {"action": "subscribe"|"unsubscribe", "order_uids": [...]}.`,
+				Tags:        []string{"events"},
+				Parameters: []parameter{
+					queryParam("uid", "Заказ, на который подписаться сразу", stringSchema()),
+				},
+				Responses: map[string]*response{
+					"101": {Description: "Соединение переключено на WebSocket"},
+					"400": {Description: "Запрос не является WebSocket-рукопожатием"},
+				},
+			},
+		},
+		apiPrefix + "/orders/{uid}":          {"get": orderResource("getOrder", "Заказ целиком", order)},
+		apiPrefix + "/orders/{uid}/items":    {"get": orderResource("getOrderItems", "Товары заказа", &schema{Type: "array", Items: componentRef("Item")})},
+		apiPrefix + "/orders/{uid}/payment":  {"get": orderResource("getOrderPayment", "Оплата заказа", componentRef("Payment"))},
+		apiPrefix + "/orders/{uid}/delivery": {"get": orderResource("getOrderDelivery", "Доставка заказа", componentRef("Delivery"))},
+		apiPrefix + "/orders/{uid}/status": {
+			"get": {
+				OperationID: "getOrderStatus",
+				Summary:     "Текущий статус, допустимые переходы и история",
+				Tags:        []string{"status"},
+				Parameters:  []parameter{uid},
+				Responses: map[string]*response{
+					"200": jsonResponse("Статус заказа", status),
+					"404": notFound,
+					"429": tooMany,
+					"500": internal,
+					"503": unavailable,
+				},
+			},
+			"patch": {
+				OperationID: "updateOrderStatus",
+				Summary:     "Смена статуса заказа",
+				Tags:        []string{"status"},
+				Parameters:  []parameter{uid},
+				RequestBody: &requestBody{Required: true, Content: jsonContent(statusUpdate)},
+				Responses: map[string]*response{
+					"200": jsonResponse("Заказ после смены статуса", order),
+					"400": errorResponseOf("Некорректное тело или неизвестный статус"),
+					"404": notFound,
+					"409": errorResponseOf("Переход недопустим; details содержит from, to и allowed_transitions"),
+					"500": internal,
+					"503": unavailable,
+				},
+			},
+		},
+		apiPrefix + "/tracks/{track}/orders": {
+			"get": {
+				OperationID: "getOrdersByTrackNumber",
+				Summary:     "Заказы по трек-номеру заказа или товара",
+				Tags:        []string{"lookup"},
+				Parameters:  []parameter{pathParam("track", "Трек-номер")},
+				Responses: map[string]*response{
+					"200": jsonResponse("Найденные заказы, новые первыми; truncated - найдено больше лимита", lookup),
+					"429": tooMany,
+					"500": internal,
+				},
+			},
+		},
+		apiPrefix + "/customers/{customerID}/orders": {
+			"get": {
+				OperationID: "getOrdersByCustomer",
+				Summary:     "Заказы покупателя",
+				Tags:        []string{"lookup"},
+				Parameters:  []parameter{pathParam("customerID", "Идентификатор покупателя")},
+				Responses: map[string]*response{
+					"200": jsonResponse("Найденные заказы, новые первыми; truncated - найдено больше лимита", lookup),
+					"429": tooMany,
+					"500": internal,
+				},
+			},
+		},
+		"/healthz": {
+			"get": {
+				OperationID: "getHealthz",
+				Summary:     "Процесс жив",
+				Tags:        []string{"meta"},
+				Responses: map[string]*response{
+					"200": jsonResponse("Сервис обрабатывает запросы", liveness),
+				},
+			},
+		},
+		"/readyz": {
+			"get": {
+				OperationID: "getReadyz",
+				Summary:     "Готовность принимать трафик с разбором по зависимостям",
+				Tags:        []string{"meta"},
+				Responses: map[string]*response{
+					"200": jsonResponse("Все зависимости доступны", readiness),
+					"503": jsonResponse("Зависимость недоступна, кэш не прогрет или сервис останавливается", readiness),
+				},
+			},
+		},
+		"/order": {
+			"get": {
+				OperationID: "getOrderLegacy",
+				Summary:     "Устаревший алиас для /api/v1/orders/{uid}",
+				Tags:        []string{"orders"},
+				Deprecated:  true,
+				Parameters: []parameter{
+					{Name: "uid", In: "query", Required: true, Schema: stringSchema()},
+				},
+				Responses: map[string]*response{
+					"200": jsonResponse("Заказ целиком", order),
+					"304": {Description: "Клиентская копия актуальна"},
+					"400": badRequest,
+					"404": notFound,
+					"429": tooMany,
+					"503": unavailable,
+				},
+			},
+		},
+		apiPrefix + "/admin/ratelimits": {
+			"get": {
+				OperationID: "getRateLimits",
+				Summary:     "Лимиты частоты запросов и текущие бюджеты клиентов",
+				Tags:        []string{"admin"},
+				Responses: map[string]*response{
+					"200": jsonResponse("Лимиты и клиенты, больше всего отклонённых запросов - первыми", rateLimits),
+				},
+			},
+		},
+		apiPrefix + "/admin/cache": {
+			"get": {
+				OperationID: "getCacheStats",
+				Summary:     "Размер кэша по шардам, доля попаданий и состояние прогрева",
+				Tags:        []string{"admin"},
+				Responses: map[string]*response{
+					"200": jsonResponse("Статистика кэша и прогрева", cacheState),
+				},
+			},
+			"delete": {
+				OperationID: "flushCache",
+				Summary:     "Очистить кэш",
+				Tags:        []string{"admin"},
+				Responses: map[string]*response{
+					"200": jsonResponse("Сколько заказов удалено", cacheFlush),
+				},
+			},
+		},
+		apiPrefix + "/admin/cache/rewarm": {
+			"post": {
+				OperationID: "rewarmCache",
+				Summary:     "Запустить прогрев кэша из PostgreSQL",
+				Description: "Прогрев идёт в фоне; ход виден в warmup ответа GET /api/v1/admin/cache. " +
+					"Без limit и since загружается то же окно, что при старте сервиса.",
+				Tags: []string{"admin"},
+				Parameters: []parameter{
+					queryParam("limit", "Сколько самых новых заказов загрузить, 0 - без ограничения", &schema{Type: "integer", Minimum: floatPtr(0)}),
+					queryParam("since", "Загрузить заказы, созданные за этот период (Go duration, например 24h)", stringSchema()),
+					queryParam("flush", "Очистить кэш перед загрузкой", &schema{Type: "boolean"}),
+				},
+				Responses: map[string]*response{
+					"202": jsonResponse("Прогрев запущен", cacheWarmup),
+					"400": badRequest,
+					"409": errorResponseOf("Прогрев уже идёт"),
+				},
+			},
+		},
+		apiPrefix + "/admin/cache/orders/{uid}": {
+			"get": {
+				OperationID: "getCacheEntry",
+				Summary:     "Заказ в том виде, в каком он лежит в кэше",
+				Tags:        []string{"admin"},
+				Parameters:  []parameter{uid},
+				Responses: map[string]*response{
+					"200": jsonResponse("Заказ из кэша", order),
+					"404": errorResponseOf("Заказа нет в кэше"),
+				},
+			},
+			"delete": {
+				OperationID: "evictCacheEntry",
+				Summary:     "Удалить заказ из кэша",
+				Tags:        []string{"admin"},
+				Parameters:  []parameter{uid},
+				Responses: map[string]*response{
+					"204": {Description: "Заказ удалён из кэша"},
+					"404": errorResponseOf("Заказа нет в кэше"),
+				},
+			},
+		},
+	}
+
+	applySecurity(paths, routes)
+
+	return &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "Order Service API",
+			Version:     "1.0.0",
+			Description: "Чтение, приём и отслеживание заказов.",
+		},
+		Paths: paths,
+		Components: openAPIComponents{
+			Schemas: reg.schemas,
+			SecuritySchemes: map[string]securityScheme{
+				"bearerAuth": {
+					Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+					Description: "JWT (HS256/RS256) со scope в claim scope или scopes; также принимается API-ключ",
+				},
+				"apiKey": {Type: "apiKey", Name: apiKeyHeader, In: "header"},
+			},
+		},
+	}
+}
+
+// applySecurity помечает операции, требующие scope, и добавляет им ответы 401 и 403.
+func applySecurity(paths map[string]map[string]*operation, routes []route) {
+	for _, rt := range routes {
+		if rt.scope == "" {
+			continue
+		}
+		method, path, _ := strings.Cut(rt.pattern, " ")
+		op := paths[path][strings.ToLower(method)]
+		if op == nil {
+			continue
+		}
+		op.Security = []map[string][]string{{"bearerAuth": {}}, {"apiKey": {}}}
+		op.Scopes = []string{string(rt.scope)}
+		op.Responses["401"] = errorResponseOf("Нет учётных данных или они недействительны")
+		op.Responses["403"] = errorResponseOf("Не хватает scope " + string(rt.scope))
+	}
+}
+
+func ifNoneMatchParam() parameter {
+	return parameter{Name: "If-None-Match", In: "header", Description: "ETag клиентской копии", Schema: stringSchema()}
+}
+
+func ifModifiedSinceParam() parameter {
+	return parameter{Name: "If-Modified-Since", In: "header", Description: "Время клиентской копии (HTTP-date)", Schema: stringSchema()}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"l0/internal/domain"
+)
+
+// schema - подмножество JSON Schema, используемое в OpenAPI 3.0.
+type schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *schema            `json:"items,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	OneOf       []*schema          `json:"oneOf,omitempty"`
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	orderStatusType = reflect.TypeOf(domain.OrderStatus(""))
+)
+
+// schemaRegistry строит схемы по Go-типам и собирает именованные структуры в components.schemas,
+// так что модель описывается ровно теми JSON-тегами и правилами валидации, что и в коде.
+type schemaRegistry struct {
+	names   map[reflect.Type]string
+	schemas map[string]*schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		names:   make(map[reflect.Type]string),
+		schemas: make(map[string]*schema),
+	}
+}
+
+// define регистрирует тип под именем компонента и возвращает ссылку на него.
+func (r *schemaRegistry) define(name string, v interface{}) *schema {
+	t := reflect.TypeOf(v)
+	r.names[t] = name
+	if _, ok := r.schemas[name]; !ok {
+		r.schemas[name] = r.structSchema(t)
+	}
+	return componentRef(name)
+}
+
+func componentRef(name string) *schema {
+	return &schema{Ref: "#/components/schemas/" + name}
+}
+
+// schemaOf возвращает схему значения; именованные структуры подставляются ссылкой.
+func (r *schemaRegistry) schemaOf(v interface{}) *schema {
+	return r.typeSchema(reflect.TypeOf(v))
+}
+
+func (r *schemaRegistry) typeSchema(t reflect.Type) *schema {
+	if name, ok := r.names[t]; ok {
+		return componentRef(name)
+	}
+
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t == orderStatusType:
+		return &schema{Type: "string", Enum: statusNames()}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return r.typeSchema(t.Elem())
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &schema{Type: "array", Items: r.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object"}
+	case reflect.Struct:
+		return r.structSchema(t)
+	default:
+		// interface{}: произвольное значение.
+		return &schema{}
+	}
+}
+
+// structSchema описывает структуру по JSON-тегам. Для входных моделей обязательность
+// берётся из правил validate, для остальных структур обязательны поля без omitempty.
+func (r *schemaRegistry) structSchema(t reflect.Type) *schema {
+	validated := false
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("validate"); ok {
+			validated = true
+			break
+		}
+	}
+
+	s := &schema{Type: "object", Properties: make(map[string]*schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := r.typeSchema(field.Type)
+		required := applyValidationRules(prop, field.Tag.Get("validate"))
+		s.Properties[name] = prop
+		if !validated {
+			required = !strings.Contains(opts, "omitempty") && !strings.Contains(opts, "omitzero")
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// applyValidationRules переносит правила validator в ограничения схемы и сообщает,
+// обязательно ли поле во входных данных.
+func applyValidationRules(s *schema, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			// Правила после dive относятся к элементам и уже описаны в их схеме.
+			return required
+		case "required", "present":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			applyBound(s, name, n)
+		}
+	}
+	return required
+}
+
+func applyBound(s *schema, rule string, n int) {
+	if s.Type == "array" {
+		if rule == "min" {
+			s.MinItems = &n
+		} else {
+			s.MaxItems = &n
+		}
+		return
+	}
+	v := float64(n)
+	if rule == "min" {
+		s.Minimum = &v
+	} else {
+		s.Maximum = &v
+	}
+}
+
+func statusNames() []string {
+	statuses := domain.Statuses()
+	names := make([]string, len(statuses))
+	for i, status := range statuses {
+		names[i] = string(status)
+	}
+	return names
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOpenAPI_MatchesRoutes падает, если маршрут добавлен в обработчик, но не описан
+// в OpenAPI-документе, или наоборот.
+func TestOpenAPI_MatchesRoutes(t *testing.T) {
+	handler, _, _ := newTestHandler(t)
+	h := handler.(*Handler)
+
+	var registered []string
+	for _, rt := range h.routes {
+		registered = append(registered, rt.pattern)
+	}
+
+	var documented []string
+	for path, operations := range buildOpenAPI(h.routes).Paths {
+		for method := range operations {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(registered)
+	sort.Strings(documented)
+	assert.Equal(t, registered, documented)
+}
+
+func testRoutes(t *testing.T) []route {
+	t.Helper()
+	handler, _, _ := newTestHandler(t)
+	return handler.(*Handler).routes
+}
+
+func TestOpenAPI_PathParametersDeclared(t *testing.T) {
+	placeholder := regexp.MustCompile(`\{([^}]+)\}`)
+
+	for path, operations := range buildOpenAPI(testRoutes(t)).Paths {
+		for method, op := range operations {
+			declared := make(map[string]bool)
+			for _, p := range op.Parameters {
+				if p.In == "path" {
+					declared[p.Name] = true
+				}
+			}
+			for _, m := range placeholder.FindAllStringSubmatch(path, -1) {
+				assert.True(t, declared[m[1]], "%s %s: path parameter %q is not declared", method, path, m[1])
+			}
+			assert.NotEmpty(t, op.Responses, "%s %s has no responses", method, path)
+		}
+	}
+}
+
+func TestOpenAPI_ReferencesResolve(t *testing.T) {
+	doc := buildOpenAPI(testRoutes(t))
+	raw, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	for _, m := range regexp.MustCompile(`"\$ref":"#/components/schemas/([^"]+)"`).FindAllStringSubmatch(string(raw), -1) {
+		assert.Contains(t, doc.Components.Schemas, m[1])
+	}
+}
+
+func TestOpenAPI_SchemasFollowModels(t *testing.T) {
+	schemas := buildOpenAPI(testRoutes(t)).Components.Schemas
+
+	order := schemas["Order"]
+	require.NotNil(t, order)
+	assert.Contains(t, order.Required, "order_uid")
+	assert.Contains(t, order.Required, "sm_id")
+	assert.NotContains(t, order.Required, "internal_signature")
+	assert.Equal(t, "date-time", order.Properties["date_created"].Format)
+	assert.Equal(t, "#/components/schemas/Delivery", order.Properties["delivery"].Ref)
+	assert.Equal(t, 1, *order.Properties["items"].MinItems)
+	assert.Equal(t, "#/components/schemas/Item", order.Properties["items"].Items.Ref)
+	assert.Contains(t, order.Properties["status"].Enum, "shipped")
+
+	item := schemas["Item"]
+	assert.Equal(t, 0.0, *item.Properties["price"].Minimum)
+	assert.Equal(t, 100.0, *item.Properties["sale"].Maximum)
+	assert.Equal(t, "integer", item.Properties["chrt_id"].Type)
+
+	assert.Equal(t, "email", schemas["Delivery"].Properties["email"].Format)
+	assert.Equal(t, []string{"error"}, schemas["Error"].Required)
+}
+
+func TestOpenAPI_DescribesScopes(t *testing.T) {
+	paths := buildOpenAPI(testRoutes(t)).Paths
+
+	assert.Equal(t, []string{"orders:read"}, paths["/api/v1/orders/{uid}"]["get"].Scopes)
+	assert.Equal(t, []string{"orders:write"}, paths["/api/v1/orders"]["post"].Scopes)
+	assert.Contains(t, paths["/api/v1/orders"]["post"].Responses, "403")
+	assert.Empty(t, paths["/api/openapi.json"]["get"].Security)
+}
+
+func TestHandler_ServesOpenAPI(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	rec := serve(h, http.MethodGet, "/api/openapi.json")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var doc map[string]interface{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+	assert.Contains(t, doc["paths"], "/api/v1/orders/{uid}")
+}
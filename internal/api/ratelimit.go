@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"l0/internal/auth"
+	"l0/internal/ratelimit"
+)
+
+// WithRateLimit включает ограничение частоты запросов по API-ключу или IP-адресу клиента.
+func WithRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(h *Handler) {
+		h.limiter = limiter
+	}
+}
+
+// rateLimited привязывает к запросу бюджет клиента. Сам бюджет списывается там, где
+// становится ясно, обслужен запрос из кэша или потребовал обращения к БД.
+func (h *Handler) rateLimited(next http.HandlerFunc) http.HandlerFunc {
+	if h.limiter == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := ratelimit.NewContext(r.Context(), h.limiter, clientKey(r))
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// clientKey - ключ бюджета: имя API-ключа или subject токена, а для анонимных
+// запросов - IP-адрес. X-Forwarded-For не учитывается, его может подделать сам клиент.
+func clientKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return "principal:" + principal.Subject
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// writeRateLimited отвечает 429 с Retry-After, если err - исчерпанный бюджет.
+func writeRateLimited(w http.ResponseWriter, err error) bool {
+	var limitErr *ratelimit.LimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(limitErr.RetryAfterSeconds()))
+	writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+	return true
+}
+
+type rateLimitsResponse struct {
+	Enabled bool                                 `json:"enabled"`
+	Limits  map[ratelimit.Budget]ratelimit.Limit `json:"limits"`
+	Clients []ratelimit.ClientState              `json:"clients"`
+}
+
+// getRateLimits показывает лимиты и текущие бюджеты клиентов.
+func (h *Handler) getRateLimits(w http.ResponseWriter, r *http.Request) {
+	if h.limiter == nil {
+		writeJSON(w, http.StatusOK, rateLimitsResponse{Limits: map[ratelimit.Budget]ratelimit.Limit{}, Clients: []ratelimit.ClientState{}})
+		return
+	}
+	state := h.limiter.Snapshot()
+	writeJSON(w, http.StatusOK, rateLimitsResponse{Enabled: true, Limits: state.Limits, Clients: state.Clients})
+}
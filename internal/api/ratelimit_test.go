@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/models"
+	"l0/internal/ratelimit"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitedHandler(t *testing.T, cfg ratelimit.Config) (http.Handler, *cache.MockCache, *db.MockDatabase) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	mockCache := cache.NewMockCache(ctrl)
+	mockDB := db.NewMockDatabase(ctrl)
+	return NewHandler(mockCache, mockDB, WithRateLimit(ratelimit.New(cfg))), mockCache, mockDB
+}
+
+func serveFrom(h http.Handler, remoteAddr, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit_CacheHits(t *testing.T) {
+	h, mockCache, _ := newRateLimitedHandler(t, ratelimit.Config{
+		Cache: ratelimit.Limit{Rate: 0.5, Burst: 2},
+		DB:    ratelimit.Limit{Rate: 1, Burst: 1},
+	})
+	mockCache.EXPECT().Get("o-1").Return(testOrder("o-1"), true).AnyTimes()
+
+	for i := 0; i < 2; i++ {
+		require.Equal(t, http.StatusOK, serveFrom(h, "10.0.0.1:5000", "/api/v1/orders/o-1").Code)
+	}
+
+	rec := serveFrom(h, "10.0.0.1:5001", "/api/v1/orders/o-1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "Rate limit exceeded", decodeError(t, rec).Error)
+
+	assert.Equal(t, http.StatusOK, serveFrom(h, "10.0.0.2:5000", "/api/v1/orders/o-1").Code)
+}
+
+func TestRateLimit_DBFallbacks(t *testing.T) {
+	h, mockCache, mockDB := newRateLimitedHandler(t, ratelimit.Config{
+		Cache: ratelimit.Limit{Rate: 100, Burst: 100},
+		DB:    ratelimit.Limit{Rate: 1, Burst: 1},
+	})
+	order := testOrder("o-2")
+	mockCache.EXPECT().Get(gomock.Any()).Return(models.Order{}, false).Times(2)
+	mockCache.EXPECT().Missing(gomock.Any()).Return(false).AnyTimes()
+	mockCache.EXPECT().Peek(gomock.Any()).Return(models.Order{}, false).AnyTimes()
+	mockDB.EXPECT().GetOrder(gomock.Any(), "o-2").Return(&order, nil).Times(1)
+	mockCache.EXPECT().Set("o-2", gomock.Any())
+
+	require.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api/v1/orders/o-2").Code)
+
+	// Второй промах не доходит до БД.
+	rec := serve(h, http.MethodGet, "/api/v1/orders/o-3")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	// Список всегда читается из БД и расходует тот же бюджет.
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, http.MethodGet, "/api/v1/orders").Code)
+
+	// Ответы из кэша остаются доступны.
+	mockCache.EXPECT().Get("o-2").Return(order, true)
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api/v1/orders/o-2").Code)
+}
+
+func TestRateLimit_LookupUsesBudgetOfSource(t *testing.T) {
+	h, mockCache, mockDB := newRateLimitedHandler(t, ratelimit.Config{
+		Cache: ratelimit.Limit{Rate: 100, Burst: 100},
+		DB:    ratelimit.Limit{Rate: 1, Burst: 1},
+	})
+	mockCache.EXPECT().Lookup(cache.ByCustomer, "c-1").Return(nil, false)
+	mockDB.EXPECT().FindOrdersByCustomer(gomock.Any(), "c-1", gomock.Any()).Return([]models.Order{testOrder("o-4")}, nil)
+	mockCache.EXPECT().StoreLookup(cache.ByCustomer, "c-1", gomock.Any())
+	require.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api/v1/customers/c-1/orders").Code)
+
+	mockCache.EXPECT().Lookup(cache.ByCustomer, "c-2").Return(nil, false)
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, http.MethodGet, "/api/v1/customers/c-2/orders").Code)
+
+	mockCache.EXPECT().Lookup(cache.ByCustomer, "c-1").Return([]models.Order{testOrder("o-4")}, true)
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, "/api/v1/customers/c-1/orders").Code)
+}
+
+func TestRateLimit_AdminEndpoint(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{
+		Cache: ratelimit.Limit{Rate: 1, Burst: 1},
+		DB:    ratelimit.Limit{Rate: 1, Burst: 1},
+	})
+	h, mockCache := newAuthTestHandler(t, WithRateLimit(limiter))
+	mockCache.EXPECT().Get("o-5").Return(testOrder("o-5"), true).Times(2)
+
+	reader := map[string]string{"X-API-Key": "reader-key"}
+	require.Equal(t, http.StatusOK, requestWith(h, http.MethodGet, "/api/v1/orders/o-5", reader).Code)
+	require.Equal(t, http.StatusTooManyRequests, requestWith(h, http.MethodGet, "/api/v1/orders/o-5", reader).Code)
+
+	assert.Equal(t, http.StatusForbidden, requestWith(h, http.MethodGet, "/api/v1/admin/ratelimits", reader).Code)
+
+	rec := requestWith(h, http.MethodGet, "/api/v1/admin/ratelimits", map[string]string{"X-API-Key": "admin-key"})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body rateLimitsResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.True(t, body.Enabled)
+	assert.Equal(t, ratelimit.Limit{Rate: 1, Burst: 1}, body.Limits[ratelimit.BudgetDB])
+	require.Len(t, body.Clients, 1)
+	assert.Equal(t, "principal:reports", body.Clients[0].Key)
+	assert.Equal(t, ratelimit.BucketState{Allowed: 1, Rejected: 1}, body.Clients[0].Budgets[ratelimit.BudgetCache])
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	rec := serve(h, http.MethodGet, "/api/v1/admin/ratelimits")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"enabled": false, "limits": {}, "clients": []}`, rec.Body.String())
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"l0/internal/auth"
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/events"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func piiTestOrder(uid string) models.Order {
+	order := testOrder(uid)
+	order.Delivery.Zip = "2639809"
+	order.Delivery.Address = "Ploshad Mira 15"
+	order.Delivery.Email = "test@gmail.com"
+	return order
+}
+
+func decodeDelivery(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var order map[string]interface{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&order))
+	return order["delivery"].(map[string]interface{})
+}
+
+func TestRedaction_GetOrderPerRole(t *testing.T) {
+	h, mockCache := newAuthTestHandler(t)
+	mockCache.EXPECT().Get("p-1").Return(piiTestOrder("p-1"), true).AnyTimes()
+
+	t.Run("admin", func(t *testing.T) {
+		rec := requestWith(h, http.MethodGet, "/api/v1/orders/p-1", map[string]string{"X-API-Key": "admin-key"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		delivery := decodeDelivery(t, rec)
+		assert.Equal(t, "Test User", delivery["name"])
+		assert.Equal(t, "+79161234567", delivery["phone"])
+		assert.Equal(t, "Ploshad Mira 15", delivery["address"])
+		assert.Equal(t, "test@gmail.com", delivery["email"])
+	})
+
+	t.Run("support", func(t *testing.T) {
+		rec := requestWith(h, http.MethodGet, "/api/v1/orders/p-1", map[string]string{"X-API-Key": "support-key"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		delivery := decodeDelivery(t, rec)
+		assert.Equal(t, "Test User", delivery["name"])
+		assert.Equal(t, "+7******4567", delivery["phone"])
+		assert.Equal(t, "****@gmail.com", delivery["email"])
+		assert.NotContains(t, delivery, "address")
+		assert.NotContains(t, delivery, "zip")
+	})
+
+	t.Run("analytics", func(t *testing.T) {
+		rec := requestWith(h, http.MethodGet, "/api/v1/orders/p-1", map[string]string{"X-API-Key": "reader-key"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		delivery := decodeDelivery(t, rec)
+		for _, field := range []string{"name", "phone", "zip", "address", "email"} {
+			assert.NotContains(t, delivery, field)
+		}
+		assert.Equal(t, "Moscow", delivery["city"])
+	})
+}
+
+func TestRedaction_ETagDependsOnRole(t *testing.T) {
+	h, mockCache := newAuthTestHandler(t)
+	mockCache.EXPECT().Get("p-2").Return(piiTestOrder("p-2"), true).Times(2)
+
+	admin := requestWith(h, http.MethodGet, "/api/v1/orders/p-2/delivery", map[string]string{"X-API-Key": "admin-key"})
+	analytics := requestWith(h, http.MethodGet, "/api/v1/orders/p-2/delivery", map[string]string{"X-API-Key": "reader-key"})
+	assert.NotEqual(t, admin.Header().Get("ETag"), analytics.Header().Get("ETag"))
+	assert.NotContains(t, analytics.Body.String(), "+7")
+}
+
+func TestRedaction_ListDoesNotModifyCache(t *testing.T) {
+	h, mockCache := newAuthTestHandler(t)
+	cached := []models.Order{piiTestOrder("p-3")}
+	mockCache.EXPECT().Lookup(cache.ByCustomer, "cust").Return(cached, true)
+
+	rec := requestWith(h, http.MethodGet, "/api/v1/customers/cust/orders", map[string]string{"X-API-Key": "reader-key"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "+79161234567")
+	assert.Equal(t, "+79161234567", cached[0].Delivery.Phone)
+}
+
+func TestRedaction_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDatabase(ctrl)
+	h := NewHandler(cache.NewMockCache(ctrl), mockDB, WithAuth(auth.New(auth.Config{APIKeys: map[string]auth.Principal{
+		"support-key": {Subject: "crm", Scopes: []auth.Scope{auth.ScopeOrdersRead}, Role: auth.RoleSupport},
+	}})))
+	mockDB.EXPECT().StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(streamOrders(piiTestOrder("p-4")))
+
+	rec := requestWith(h, http.MethodGet, "/api/v1/orders/export", map[string]string{"X-API-Key": "support-key"})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	row := make(map[string]string)
+	for i, name := range records[0] {
+		row[name] = records[1][i]
+	}
+	assert.Equal(t, "'+7******4567", row["delivery_phone"], "masked phone is still escaped as a formula")
+	assert.Empty(t, row["delivery_address"])
+	assert.Equal(t, "1000", row["payment_amount"])
+}
+
+func TestRedaction_Stream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	broker := events.NewBroker(10)
+	h := NewHandler(cache.NewMockCache(ctrl), db.NewMockDatabase(ctrl), WithEvents(broker), WithAuth(auth.New(auth.Config{
+		APIKeys: map[string]auth.Principal{
+			"reader-key": {Subject: "bi", Scopes: []auth.Scope{auth.ScopeOrdersRead}, Role: auth.RoleAnalytics},
+		},
+	})))
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	broker.Publish(events.OrderCreated, piiTestOrder("p-0"))
+	broker.Publish(events.OrderCreated, piiTestOrder("p-5"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/orders/stream?last_event_id=1&access_token=reader-key", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok && strings.Contains(data, "p-5") {
+			assert.NotContains(t, data, "Test User")
+			assert.NotContains(t, data, "+79161234567")
+			return
+		}
+	}
+	t.Fatal("order event was not received")
+}
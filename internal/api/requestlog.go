@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bufio"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"l0/internal/logging"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// maxRequestIDLength ограничивает идентификатор, пришедший от клиента: он попадает в каждую строку лога.
+const maxRequestIDLength = 128
+
+// logRequest присваивает запросу X-Request-ID (или принимает идентификатор клиента),
+// кладёт в контекст логгер с ним и после ответа пишет строку лога с методом, путём,
+// статусом, временем обработки и тем, был ли запрос обслужен из кэша.
+func (h *Handler) logRequest(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	start := time.Now()
+
+	requestID := r.Header.Get(requestIDHeader)
+	if !validRequestID(requestID) {
+		requestID = logging.NewRequestID()
+	}
+	w.Header().Set(requestIDHeader, requestID)
+
+	logger := logging.FromContext(r.Context()).With("request_id", requestID)
+	stats := &logging.CacheStats{}
+	ctx := logging.WithRequestID(r.Context(), requestID)
+	ctx = logging.WithLogger(ctx, logger)
+	ctx = logging.WithCacheStats(ctx, stats)
+
+	rec := &statusRecorder{ResponseWriter: w}
+	defer func() {
+		// Прерванный через panic(http.ErrAbortHandler) ответ тоже попадает в лог.
+		p := recover()
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		switch {
+		case p != nil:
+			level = slog.LevelError
+		case probePaths[r.URL.Path]:
+			// Пробы оркестратора приходят каждые несколько секунд; смену готовности
+			// логирует сам getReadyz.
+			level = slog.LevelDebug
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("bytes", rec.bytes),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			stats.Attr(),
+		}
+		if p != nil {
+			attrs = append(attrs, slog.Bool("aborted", true))
+		}
+		logger.LogAttrs(ctx, level, "HTTP request", attrs...)
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	next.ServeHTTP(rec, r.WithContext(ctx))
+}
+
+// validRequestID принимает только короткие идентификаторы из безопасных символов,
+// чтобы клиент не мог подделать строки лога.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// statusRecorder запоминает статус и размер ответа. Flush и Hijack пробрасываются
+// для SSE и WebSocket.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status int
+	bytes  int64
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(p)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+func (rec *statusRecorder) Flush() {
+	_ = http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(rec.ResponseWriter).Hijack()
+	if err == nil {
+		rec.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"l0/internal/db"
+	"l0/internal/logging"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveLogged выполняет запрос и возвращает записи JSON-лога, сделанные во время него.
+func serveLogged(t *testing.T, h http.Handler, req *http.Request) (*httptest.ResponseRecorder, []map[string]interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	req = req.WithContext(logging.WithLogger(req.Context(), logger))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return rec, entries
+}
+
+func TestRequestLog_AssignsRequestID(t *testing.T) {
+	h, mockCache, _ := newTestHandler(t)
+	mockCache.EXPECT().Get("o-1").Return(testOrder("o-1"), true)
+
+	rec, entries := serveLogged(t, h, httptest.NewRequest(http.MethodGet, "/api/v1/orders/o-1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	id := rec.Header().Get(requestIDHeader)
+	assert.Len(t, id, 32)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, "HTTP request", entry["msg"])
+	assert.Equal(t, id, entry["request_id"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/api/v1/orders/o-1", entry["path"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.Equal(t, "hit", entry["cache"])
+	assert.Contains(t, entry, "latency_ms")
+}
+
+func TestRequestLog_PropagatesRequestID(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+	mockCache.EXPECT().Get("missing").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("missing").Return(false)
+	mockCache.EXPECT().Peek("missing").Return(models.Order{}, false)
+	mockDB.EXPECT().GetOrder(gomock.Any(), "missing").DoAndReturn(func(ctx context.Context, _ string) (*models.Order, error) {
+		assert.Equal(t, "client-id-1", logging.RequestIDFromContext(ctx))
+		return nil, db.ErrOrderNotFound
+	})
+	mockCache.EXPECT().MarkMissing("missing")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/missing", nil)
+	req.Header.Set(requestIDHeader, "client-id-1")
+	rec, entries := serveLogged(t, h, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "client-id-1", rec.Header().Get(requestIDHeader))
+
+	// Строка обработчика и строка запроса несут один request_id.
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Order not found", entries[0]["msg"])
+	assert.Equal(t, "missing", entries[0]["order_uid"])
+	for _, entry := range entries {
+		assert.Equal(t, "client-id-1", entry["request_id"])
+	}
+	assert.Equal(t, "miss", entries[1]["cache"])
+}
+
+func TestRequestLog_ReplacesUnsafeRequestID(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	for _, id := range []string{"bad id\nlevel=ERROR", strings.Repeat("a", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/api/unknown", nil)
+		req.Header.Set(requestIDHeader, id)
+		rec, entries := serveLogged(t, h, req)
+
+		assert.NotEqual(t, id, rec.Header().Get(requestIDHeader))
+		assert.Len(t, rec.Header().Get(requestIDHeader), 32)
+		require.Len(t, entries, 1)
+		assert.Equal(t, float64(http.StatusNotFound), entries[0]["status"])
+		assert.NotContains(t, entries[0], "cache")
+	}
+}
+
+func TestRequestLog_PartialCacheHit(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+	mockCache.EXPECT().Get("b-1").Return(testOrder("b-1"), true)
+	mockCache.EXPECT().Get("b-2").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("b-2").Return(false)
+	mockDB.EXPECT().GetOrders(gomock.Any(), []string{"b-2"}).Return(nil, nil)
+	mockCache.EXPECT().MarkMissing("b-2")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orders:batchGet", strings.NewReader(`{"order_uids":["b-1","b-2"]}`))
+	rec, entries := serveLogged(t, h, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "partial", entries[len(entries)-1]["cache"])
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"l0/internal/auth"
+	"l0/internal/db"
+	"l0/internal/domain"
+	"l0/internal/events"
+	"l0/internal/logging"
+	"l0/internal/models"
+	"l0/internal/ratelimit"
+	"l0/internal/redact"
+)
+
+type statusUpdateRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type statusResponse struct {
+	Status             string                `json:"status"`
+	AllowedTransitions []domain.OrderStatus  `json:"allowed_transitions"`
+	History            []models.StatusChange `json:"history"`
+}
+
+type transitionErrorDetails struct {
+	From               domain.OrderStatus   `json:"from"`
+	To                 domain.OrderStatus   `json:"to"`
+	AllowedTransitions []domain.OrderStatus `json:"allowed_transitions"`
+}
+
+func (h *Handler) getOrderStatus(w http.ResponseWriter, r *http.Request) {
+	uid := r.PathValue("uid")
+	order, ok := h.orderFromRequest(w, r, uid)
+	if !ok {
+		return
+	}
+
+	if writeRateLimited(w, ratelimit.Charge(r.Context(), ratelimit.BudgetDB)) {
+		return
+	}
+	history, err := h.db.GetStatusHistory(r.Context(), uid)
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to get status history", "order_uid", uid, "error", err)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	status := domain.OrderStatus(order.Status)
+	writeJSON(w, http.StatusOK, statusResponse{
+		Status:             order.Status,
+		AllowedTransitions: status.AllowedTransitions(),
+		History:            history,
+	})
+}
+
+// updateOrderStatus переводит заказ в новый статус. После успешного перехода
+// запись в кэше сбрасывается и заново загружается из БД.
+func (h *Handler) updateOrderStatus(w http.ResponseWriter, r *http.Request) {
+	uid := r.PathValue("uid")
+
+	var req statusUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	to, err := domain.ParseOrderStatus(req.Status)
+	if err != nil {
+		writeErrorDetails(w, http.StatusBadRequest, "Unknown order status", map[string]interface{}{
+			"allowed_statuses": domain.Statuses(),
+		})
+		return
+	}
+
+	_, err = h.db.UpdateOrderStatus(r.Context(), uid, to, req.Reason)
+	var transitionErr *domain.TransitionError
+	switch {
+	case errors.Is(err, db.ErrOrderNotFound):
+		writeError(w, http.StatusNotFound, "Order not found")
+		return
+	case errors.As(err, &transitionErr):
+		writeErrorDetails(w, http.StatusConflict, transitionErr.Error(), transitionErrorDetails{
+			From:               transitionErr.From,
+			To:                 transitionErr.To,
+			AllowedTransitions: transitionErr.From.AllowedTransitions(),
+		})
+		return
+	case err != nil:
+		logging.FromContext(r.Context()).Error("Failed to update order status", "order_uid", uid, "error", err)
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	h.cacheService.Remove(uid)
+
+	order, ok := h.orderFromRequest(w, r, uid)
+	if !ok {
+		return
+	}
+	h.events.Publish(events.OrderStatusChanged, *order)
+	writeJSON(w, http.StatusOK, redact.Order(*order, auth.RoleFromContext(r.Context())))
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"l0/internal/db"
+	"l0/internal/domain"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchStatus(h http.Handler, uid, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/orders/"+uid+"/status", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_UpdateOrderStatus(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	updated := testOrder("st-1")
+	updated.Status = string(domain.StatusPaid)
+
+	gomock.InOrder(
+		mockDB.EXPECT().
+			UpdateOrderStatus(gomock.Any(), "st-1", domain.StatusPaid, "payment confirmed").
+			Return(&models.StatusChange{From: "created", To: "paid"}, nil),
+		mockCache.EXPECT().Remove("st-1"),
+		mockCache.EXPECT().Get("st-1").Return(models.Order{}, false),
+		mockCache.EXPECT().Missing("st-1").Return(false),
+		mockCache.EXPECT().Peek("st-1").Return(models.Order{}, false),
+		mockDB.EXPECT().GetOrder(gomock.Any(), "st-1").Return(&updated, nil),
+		mockCache.EXPECT().Set("st-1", updated),
+	)
+
+	rec := patchStatus(h, "st-1", `{"status": "paid", "reason": "payment confirmed"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var got models.Order
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, "paid", got.Status)
+}
+
+func TestHandler_UpdateOrderStatusInvalidTransition(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+
+	mockDB.EXPECT().
+		UpdateOrderStatus(gomock.Any(), "st-2", domain.StatusDelivered, "").
+		Return(nil, &domain.TransitionError{From: domain.StatusCreated, To: domain.StatusDelivered})
+
+	rec := patchStatus(h, "st-2", `{"status": "delivered"}`)
+	require.Equal(t, http.StatusConflict, rec.Code)
+
+	var body struct {
+		Details transitionErrorDetails `json:"details"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, domain.StatusCreated, body.Details.From)
+	assert.Equal(t, []domain.OrderStatus{domain.StatusPaid, domain.StatusCancelled}, body.Details.AllowedTransitions)
+}
+
+func TestHandler_UpdateOrderStatusErrors(t *testing.T) {
+	h, _, mockDB := newTestHandler(t)
+
+	assert.Equal(t, http.StatusBadRequest, patchStatus(h, "st-3", `{"status": "lost"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, patchStatus(h, "st-3", `not json`).Code)
+
+	mockDB.EXPECT().
+		UpdateOrderStatus(gomock.Any(), "st-3", domain.StatusPaid, "").
+		Return(nil, db.ErrOrderNotFound)
+	assert.Equal(t, http.StatusNotFound, patchStatus(h, "st-3", `{"status": "paid"}`).Code)
+}
+
+func TestHandler_GetOrderStatus(t *testing.T) {
+	h, mockCache, mockDB := newTestHandler(t)
+
+	order := testOrder("st-4")
+	order.Status = string(domain.StatusShipped)
+	history := []models.StatusChange{
+		{To: "created", ChangedAt: time.Now()},
+		{From: "created", To: "paid", ChangedAt: time.Now()},
+	}
+
+	mockCache.EXPECT().Get("st-4").Return(order, true)
+	mockDB.EXPECT().GetStatusHistory(gomock.Any(), "st-4").Return(history, nil)
+
+	rec := serve(h, http.MethodGet, "/api/v1/orders/st-4/status")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body statusResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "shipped", body.Status)
+	assert.Equal(t, []domain.OrderStatus{domain.StatusDelivered, domain.StatusReturned}, body.AllowedTransitions)
+	assert.Len(t, body.History, 2)
+}
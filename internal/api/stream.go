@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"l0/internal/auth"
+	"l0/internal/events"
+	"l0/internal/logging"
+	"l0/internal/redact"
+)
+
+const streamKeepAlive = 15 * time.Second
+
+// streamOrders отдаёт поток событий о заказах в формате Server-Sent Events.
+// Поддерживает фильтры customer_id и delivery_service и продолжение с Last-Event-ID.
+func (h *Handler) streamOrders(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := orderEventFilter(query.Get("customer_id"), query.Get("delivery_service"))
+
+	role := auth.RoleFromContext(r.Context())
+	sub, missed, complete := h.events.Subscribe(lastEventID, filter)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	if !complete {
+		// Часть событий уже вытеснена из буфера: клиенту стоит перечитать данные через API.
+		fmt.Fprintf(w, "event: gap\ndata: {\"last_event_id\": %d}\n\n", lastEventID)
+	}
+	for _, event := range missed {
+		if err := writeSSEEvent(w, event, role); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		logging.FromContext(r.Context()).Error("Streaming is not supported", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.streams.Done():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := writeSSEEvent(w, event, role); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func parseLastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+func orderEventFilter(customerID, deliveryService string) events.Filter {
+	if customerID == "" && deliveryService == "" {
+		return nil
+	}
+	return func(event events.Event) bool {
+		if customerID != "" && event.Order.CustomerID != customerID {
+			return false
+		}
+		if deliveryService != "" && event.Order.DeliveryService != deliveryService {
+			return false
+		}
+		return true
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, event events.Event, role auth.Role) error {
+	data, err := json.Marshal(redact.Order(event.Order, role))
+	if err != nil {
+		slog.Error("Failed to encode event", "event_id", event.ID, "error", err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/events"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runStream открывает SSE-поток, выполняет publish и возвращает всё, что пришло в поток.
+func runStream(t *testing.T, broker *events.Broker, target string, headers map[string]string, publish func()) string {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	server := httptest.NewServer(NewHandler(cache.NewMockCache(ctrl), db.NewMockDatabase(ctrl), WithEvents(broker)))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+target, nil)
+	require.NoError(t, err)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	publish()
+
+	// Поток не заканчивается сам, читаем его до истечения таймаута.
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestHandler_StreamOrders(t *testing.T) {
+	broker := events.NewBroker(10)
+	var published events.Event
+
+	body := runStream(t, broker, "/api/v1/orders/stream?delivery_service=meest", nil, func() {
+		other := testOrder("s-1")
+		other.DeliveryService = "dhl"
+		broker.Publish(events.OrderCreated, other)
+
+		wanted := testOrder("s-2")
+		wanted.DeliveryService = "meest"
+		published = broker.Publish(events.OrderCreated, wanted)
+	})
+
+	assert.Contains(t, body, fmt.Sprintf("id: %d\nevent: order.created\n", published.ID))
+	assert.Contains(t, body, `"order_uid":"s-2"`)
+	assert.NotContains(t, body, `"order_uid":"s-1"`)
+}
+
+func TestHandler_StreamOrdersResume(t *testing.T) {
+	broker := events.NewBroker(10)
+	first := broker.Publish(events.OrderCreated, testOrder("r-1"))
+	second := broker.Publish(events.OrderCreated, testOrder("r-2"))
+
+	lastEventID := strconv.FormatUint(first.ID, 10)
+	body := runStream(t, broker, "/api/v1/orders/stream", map[string]string{"Last-Event-ID": lastEventID}, func() {})
+
+	assert.NotContains(t, body, `"order_uid":"r-1"`)
+	assert.Contains(t, body, fmt.Sprintf("id: %d\n", second.ID))
+	assert.NotContains(t, body, "event: gap")
+}
+
+func TestHandler_StreamOrdersInvalidLastEventID(t *testing.T) {
+	h, _, _ := newTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/orders/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json"))
+}
+
+func TestHandler_StreamOrdersClosedOnShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	streams, stopStreams := context.WithCancel(context.Background())
+	server := httptest.NewServer(NewHandler(cache.NewMockCache(ctrl), db.NewMockDatabase(ctrl),
+		WithEvents(events.NewBroker(10)), WithStreamContext(streams)))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/orders/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	stopStreams()
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.ReadAll(resp.Body)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream is not closed after shutdown")
+	}
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"l0/internal/auth"
+	"l0/internal/db"
+	"l0/internal/events"
+	"l0/internal/logging"
+	"l0/internal/ratelimit"
+	"l0/internal/redact"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsMaxSubscriptions = 100
+	wsWriteTimeout     = 10 * time.Second
+	wsPongTimeout      = 60 * time.Second
+	wsPingInterval     = wsPongTimeout * 9 / 10
+	wsMaxMessageBytes  = 64 << 10
+)
+
+// wsClientMessage - команда клиента: {"action": "subscribe", "order_uids": ["..."]}.
+type wsClientMessage struct {
+	Action    string   `json:"action"`
+	OrderUIDs []string `json:"order_uids"`
+}
+
+// wsServerMessage - сообщение клиенту. Для событий заполняются EventID и Order.
+type wsServerMessage struct {
+	Type      string      `json:"type"`
+	EventID   uint64      `json:"event_id,omitempty"`
+	OrderUID  string      `json:"order_uid,omitempty"`
+	OrderUIDs []string    `json:"order_uids,omitempty"`
+	Order     interface{} `json:"order,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// orderSubscriptions - набор UID, на которые подписано соединение.
+type orderSubscriptions struct {
+	mu   sync.RWMutex
+	uids map[string]struct{}
+}
+
+func (s *orderSubscriptions) has(uid string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.uids[uid]
+	return ok
+}
+
+// add добавляет UID с учётом лимита и возвращает реально добавленные.
+func (s *orderSubscriptions) add(uids []string) (added []string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, uid := range uids {
+		if _, exists := s.uids[uid]; exists || uid == "" {
+			continue
+		}
+		if len(s.uids) >= wsMaxSubscriptions {
+			return added, false
+		}
+		s.uids[uid] = struct{}{}
+		added = append(added, uid)
+	}
+	return added, true
+}
+
+func (s *orderSubscriptions) remove(uids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, uid := range uids {
+		delete(s.uids, uid)
+	}
+}
+
+func (s *orderSubscriptions) list() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	uids := make([]string, 0, len(s.uids))
+	for uid := range s.uids {
+		uids = append(uids, uid)
+	}
+	return uids
+}
+
+// subscribeOrders - WebSocket, по которому клиент подписывается на конкретные заказы
+// и получает заказ целиком при каждом его изменении. Начальные UID можно передать
+// параметрами ?uid=...&uid=..., дальнейшие - командами subscribe/unsubscribe.
+func (h *Handler) subscribeOrders(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrader уже ответил клиенту ошибкой.
+		return
+	}
+	defer conn.Close()
+
+	role := auth.RoleFromContext(r.Context())
+	subs := &orderSubscriptions{uids: make(map[string]struct{})}
+	sub, _, _ := h.events.Subscribe(0, func(e events.Event) bool {
+		return subs.has(e.Order.OrderUID)
+	})
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	outgoing := make(chan wsServerMessage, 16)
+	go h.readWebSocket(ctx, cancel, conn, subs, r.URL.Query()["uid"], outgoing)
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		var msg wsServerMessage
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.streams.Done():
+			writeWSClose(conn, websocket.CloseGoingAway, "server is shutting down")
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				writeWSClose(conn, websocket.CloseTryAgainLater, "subscriber is too slow")
+				return
+			}
+			msg = wsServerMessage{
+				Type:     string(event.Type),
+				EventID:  event.ID,
+				OrderUID: event.Order.OrderUID,
+				Order:    redact.Order(event.Order, role),
+			}
+		case msg = <-outgoing:
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+			continue
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := conn.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+}
+
+// readWebSocket читает команды клиента. Запись в соединение выполняет только
+// основной цикл, поэтому ответы отправляются через outgoing.
+func (h *Handler) readWebSocket(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	conn *websocket.Conn,
+	subs *orderSubscriptions,
+	initial []string,
+	outgoing chan<- wsServerMessage,
+) {
+	defer cancel()
+
+	if len(initial) > 0 {
+		h.handleSubscribe(ctx, subs, initial, outgoing)
+	}
+
+	conn.SetReadLimit(wsMaxMessageBytes)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		var msg wsClientMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logging.FromContext(ctx).Warn("WebSocket read error", "error", err)
+			}
+			return
+		}
+
+		switch msg.Action {
+		case "subscribe":
+			h.handleSubscribe(ctx, subs, msg.OrderUIDs, outgoing)
+		case "unsubscribe":
+			subs.remove(msg.OrderUIDs)
+			sendWS(ctx, outgoing, wsServerMessage{Type: "subscribed", OrderUIDs: subs.list()})
+		default:
+			sendWS(ctx, outgoing, wsServerMessage{Type: "error", Error: "unknown action " + msg.Action})
+		}
+	}
+}
+
+// handleSubscribe подписывает на заказы и сразу отправляет их текущее состояние.
+func (h *Handler) handleSubscribe(ctx context.Context, subs *orderSubscriptions, uids []string, outgoing chan<- wsServerMessage) {
+	added, ok := subs.add(uids)
+	if !ok {
+		sendWS(ctx, outgoing, wsServerMessage{Type: "error", Error: "too many subscriptions"})
+	}
+	sendWS(ctx, outgoing, wsServerMessage{Type: "subscribed", OrderUIDs: subs.list()})
+
+	for _, uid := range added {
+		order, err := h.orders.Get(ctx, uid)
+		var limitErr *ratelimit.LimitError
+		if errors.As(err, &limitErr) {
+			sendWS(ctx, outgoing, wsServerMessage{Type: "error", OrderUID: uid, Error: "Rate limit exceeded"})
+			continue
+		}
+		if errors.Is(err, db.ErrOrderNotFound) {
+			sendWS(ctx, outgoing, wsServerMessage{Type: "error", OrderUID: uid, Error: "Order not found"})
+			continue
+		}
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to get order", "order_uid", uid, "error", err)
+			sendWS(ctx, outgoing, wsServerMessage{Type: "error", OrderUID: uid, Error: "Order storage is unavailable"})
+			continue
+		}
+		sendWS(ctx, outgoing, wsServerMessage{
+			Type:     "order.snapshot",
+			OrderUID: uid,
+			Order:    redact.Order(*order, auth.RoleFromContext(ctx)),
+		})
+	}
+}
+
+func sendWS(ctx context.Context, outgoing chan<- wsServerMessage, msg wsServerMessage) {
+	select {
+	case outgoing <- msg:
+	case <-ctx.Done():
+	}
+}
+
+func writeWSClose(conn *websocket.Conn, code int, text string) {
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(wsWriteTimeout))
+}
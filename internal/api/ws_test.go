@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/events"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type wsTestMessage struct {
+	Type      string       `json:"type"`
+	EventID   uint64       `json:"event_id"`
+	OrderUID  string       `json:"order_uid"`
+	OrderUIDs []string     `json:"order_uids"`
+	Order     models.Order `json:"order"`
+	Error     string       `json:"error"`
+}
+
+func dialOrdersWS(t *testing.T, mockCache *cache.MockCache, mockDB *db.MockDatabase, broker *events.Broker, query string) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(NewHandler(mockCache, mockDB, WithEvents(broker)))
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/orders/ws" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readWS(t *testing.T, conn *websocket.Conn) wsTestMessage {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	var msg wsTestMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestHandler_OrdersWebSocket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCache := cache.NewMockCache(ctrl)
+	broker := events.NewBroker(10)
+
+	order := testOrder("ws-1")
+	mockCache.EXPECT().Get("ws-1").Return(order, true)
+
+	conn := dialOrdersWS(t, mockCache, db.NewMockDatabase(ctrl), broker, "?uid=ws-1")
+
+	subscribed := readWS(t, conn)
+	assert.Equal(t, "subscribed", subscribed.Type)
+	assert.Equal(t, []string{"ws-1"}, subscribed.OrderUIDs)
+
+	snapshot := readWS(t, conn)
+	assert.Equal(t, "order.snapshot", snapshot.Type)
+	assert.Equal(t, "ws-1", snapshot.Order.OrderUID)
+
+	broker.Publish(events.OrderStatusChanged, testOrder("other"))
+	changed := order
+	changed.Status = "paid"
+	published := broker.Publish(events.OrderStatusChanged, changed)
+
+	update := readWS(t, conn)
+	assert.Equal(t, "order.status_changed", update.Type)
+	assert.Equal(t, published.ID, update.EventID)
+	assert.Equal(t, "paid", update.Order.Status)
+}
+
+func TestHandler_OrdersWebSocketSubscribeCommands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCache := cache.NewMockCache(ctrl)
+	mockDB := db.NewMockDatabase(ctrl)
+	broker := events.NewBroker(10)
+
+	mockCache.EXPECT().Get("ws-2").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("ws-2").Return(false)
+	mockCache.EXPECT().Peek("ws-2").Return(models.Order{}, false)
+	mockDB.EXPECT().GetOrder(gomock.Any(), "ws-2").Return(nil, assert.AnError)
+
+	conn := dialOrdersWS(t, mockCache, mockDB, broker, "")
+
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Action: "subscribe", OrderUIDs: []string{"ws-2"}}))
+	assert.Equal(t, "subscribed", readWS(t, conn).Type)
+
+	notFound := readWS(t, conn)
+	assert.Equal(t, "error", notFound.Type)
+	assert.Equal(t, "ws-2", notFound.OrderUID)
+
+	// Подписка остаётся: заказ может появиться позже.
+	broker.Publish(events.OrderCreated, testOrder("ws-2"))
+	assert.Equal(t, "order.created", readWS(t, conn).Type)
+
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Action: "unsubscribe", OrderUIDs: []string{"ws-2"}}))
+	unsubscribed := readWS(t, conn)
+	assert.Equal(t, "subscribed", unsubscribed.Type)
+	assert.Empty(t, unsubscribed.OrderUIDs)
+
+	require.NoError(t, conn.WriteJSON(wsClientMessage{Action: "dance"}))
+	assert.Equal(t, "error", readWS(t, conn).Type)
+}
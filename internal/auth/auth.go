@@ -0,0 +1,262 @@
+// Package auth проверяет учётные данные клиентов API: статические API-ключи
+// и JWT, подписанные известным сервису ключом (HS256 или RS256).
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Scope string
+
+const (
+	ScopeOrdersRead  Scope = "orders:read"
+	ScopeOrdersWrite Scope = "orders:write"
+	// ScopeAdmin даёт доступ ко всем операциям.
+	ScopeAdmin Scope = "admin"
+)
+
+// Role определяет, какие персональные данные клиент видит в ответах.
+type Role string
+
+const (
+	// RoleAdmin видит все поля.
+	RoleAdmin Role = "admin"
+	// RoleSupport видит имя и частично замаскированные контакты.
+	RoleSupport Role = "support"
+	// RoleAnalytics не видит персональных данных. Роль по умолчанию.
+	RoleAnalytics Role = "analytics"
+)
+
+// rolePrefix помечает роль в списке scope API-ключа: "orders:read role:support".
+const rolePrefix = "role:"
+
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleAdmin, RoleSupport, RoleAnalytics:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+}
+
+// defaultRole назначается клиентам без явной роли: администраторам - admin,
+// остальным - analytics, чтобы персональные данные не раскрывались по умолчанию.
+func defaultRole(scopes []Scope) Role {
+	for _, s := range scopes {
+		if s == ScopeAdmin {
+			return RoleAdmin
+		}
+	}
+	return RoleAnalytics
+}
+
+var (
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal - аутентифицированный клиент.
+type Principal struct {
+	Subject string
+	Scopes  []Scope
+	Role    Role
+}
+
+func (p *Principal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// RoleFromContext возвращает роль клиента. Запрос без аутентифицированного клиента
+// возможен только при отключённой аутентификации и выполняется с ролью admin.
+func RoleFromContext(ctx context.Context) Role {
+	if p, ok := PrincipalFromContext(ctx); ok {
+		return p.Role
+	}
+	return RoleAdmin
+}
+
+type Config struct {
+	// APIKeys - статические ключи и их владельцы.
+	APIKeys map[string]Principal
+	// HMACSecret включает проверку JWT с алгоритмом HS256.
+	HMACSecret []byte
+	// RSAPublicKey включает проверку JWT с алгоритмом RS256.
+	RSAPublicKey *rsa.PublicKey
+	// Issuer и Audience, если заданы, должны совпадать с iss и aud токена.
+	Issuer   string
+	Audience string
+}
+
+type Authenticator struct {
+	apiKeys      map[[sha256.Size]byte]Principal
+	hmacSecret   []byte
+	rsaPublicKey *rsa.PublicKey
+	parser       *jwt.Parser
+}
+
+func New(cfg Config) *Authenticator {
+	a := &Authenticator{
+		apiKeys:      make(map[[sha256.Size]byte]Principal, len(cfg.APIKeys)),
+		hmacSecret:   cfg.HMACSecret,
+		rsaPublicKey: cfg.RSAPublicKey,
+	}
+	// Ключи хранятся в виде хэшей: поиск по map не зависит от того,
+	// сколько символов ключа совпало.
+	for key, p := range cfg.APIKeys {
+		a.apiKeys[sha256.Sum256([]byte(key))] = p
+	}
+
+	var methods []string
+	if len(cfg.HMACSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.RSAPublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) > 0 {
+		opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+		if cfg.Issuer != "" {
+			opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+		}
+		if cfg.Audience != "" {
+			opts = append(opts, jwt.WithAudience(cfg.Audience))
+		}
+		a.parser = jwt.NewParser(opts...)
+	}
+	return a
+}
+
+// Authenticate проверяет API-ключ или JWT. Строка сначала ищется среди API-ключей,
+// затем разбирается как токен, если проверка JWT настроена.
+func (a *Authenticator) Authenticate(credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrNoCredentials
+	}
+
+	if p, ok := a.apiKeys[sha256.Sum256([]byte(credential))]; ok {
+		return &p, nil
+	}
+
+	if a.parser == nil || strings.Count(credential, ".") != 2 {
+		return nil, ErrInvalidCredentials
+	}
+	return a.parseToken(credential)
+}
+
+// tokenClaims принимает scope как строку через пробел (RFC 8693) или массив scopes.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Scope  string   `json:"scope,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	Role   string   `json:"role,omitempty"`
+}
+
+func (a *Authenticator) parseToken(raw string) (*Principal, error) {
+	var claims tokenClaims
+	_, err := a.parser.ParseWithClaims(raw, &claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			return a.hmacSecret, nil
+		case *jwt.SigningMethodRSA:
+			return a.rsaPublicKey, nil
+		default:
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	p := &Principal{Subject: claims.Subject}
+	for _, s := range strings.Fields(claims.Scope) {
+		p.Scopes = append(p.Scopes, Scope(s))
+	}
+	for _, s := range claims.Scopes {
+		p.Scopes = append(p.Scopes, Scope(s))
+	}
+
+	p.Role = defaultRole(p.Scopes)
+	if claims.Role != "" {
+		role, err := ParseRole(claims.Role)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+		}
+		p.Role = role
+	}
+	return p, nil
+}
+
+// ParseAPIKeys разбирает список ключей вида "name:key:scope scope;name:key:scope".
+// Роль задаётся в списке scope как "role:support". Сам ключ не может содержать ':' и ';'.
+func ParseAPIKeys(spec string) (map[string]Principal, error) {
+	keys := make(map[string]Principal)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid API key entry %q: expected name:key:scopes", parts[0])
+		}
+
+		p := Principal{Subject: parts[0]}
+		for _, s := range strings.Fields(parts[2]) {
+			if name, ok := strings.CutPrefix(s, rolePrefix); ok {
+				role, err := ParseRole(name)
+				if err != nil {
+					return nil, fmt.Errorf("API key %q: %v", parts[0], err)
+				}
+				p.Role = role
+				continue
+			}
+			p.Scopes = append(p.Scopes, Scope(s))
+		}
+		if len(p.Scopes) == 0 {
+			return nil, fmt.Errorf("API key %q has no scopes", parts[0])
+		}
+		if p.Role == "" {
+			p.Role = defaultRole(p.Scopes)
+		}
+		keys[parts[1]] = p
+	}
+	return keys, nil
+}
+
+// LoadRSAPublicKey читает PEM-файл с публичным ключом для проверки RS256.
+func LoadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %v", err)
+	}
+	key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+	return key, nil
+}
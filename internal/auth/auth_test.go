@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("test-secret")
+
+func signHS256(t *testing.T, secret []byte, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	require.NoError(t, err)
+	return token
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "svc-billing",
+		"scope": "orders:read orders:write",
+		"iss":   "issuer",
+		"aud":   "orders-api",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	a := New(Config{APIKeys: map[string]Principal{
+		"key-1": {Subject: "reports", Scopes: []Scope{ScopeOrdersRead}},
+	}})
+
+	p, err := a.Authenticate("key-1")
+	require.NoError(t, err)
+	assert.Equal(t, "reports", p.Subject)
+	assert.True(t, p.HasScope(ScopeOrdersRead))
+	assert.False(t, p.HasScope(ScopeOrdersWrite))
+
+	_, err = a.Authenticate("key-2")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = a.Authenticate("")
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestAuthenticate_HS256(t *testing.T) {
+	a := New(Config{HMACSecret: testSecret, Issuer: "issuer", Audience: "orders-api"})
+
+	p, err := a.Authenticate(signHS256(t, testSecret, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "svc-billing", p.Subject)
+	assert.Equal(t, []Scope{ScopeOrdersRead, ScopeOrdersWrite}, p.Scopes)
+	assert.Equal(t, RoleAnalytics, p.Role)
+
+	support := validClaims()
+	support["role"] = "support"
+	p, err = a.Authenticate(signHS256(t, testSecret, support))
+	require.NoError(t, err)
+	assert.Equal(t, RoleSupport, p.Role)
+
+	unknownRole := validClaims()
+	unknownRole["role"] = "root"
+	_, err = a.Authenticate(signHS256(t, testSecret, unknownRole))
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	arrayScopes := validClaims()
+	delete(arrayScopes, "scope")
+	arrayScopes["scopes"] = []string{"admin"}
+	p, err = a.Authenticate(signHS256(t, testSecret, arrayScopes))
+	require.NoError(t, err)
+	assert.True(t, p.HasScope(ScopeOrdersWrite), "admin grants every scope")
+	assert.Equal(t, RoleAdmin, p.Role)
+}
+
+func TestAuthenticate_RejectsInvalidTokens(t *testing.T) {
+	a := New(Config{HMACSecret: testSecret, Issuer: "issuer", Audience: "orders-api"})
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	noExpiry := validClaims()
+	delete(noExpiry, "exp")
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "someone-else"
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = "other-api"
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	for name, token := range map[string]string{
+		"wrong secret":   signHS256(t, []byte("other"), validClaims()),
+		"expired":        signHS256(t, testSecret, expired),
+		"no expiry":      signHS256(t, testSecret, noExpiry),
+		"wrong issuer":   signHS256(t, testSecret, wrongIssuer),
+		"wrong audience": signHS256(t, testSecret, wrongAudience),
+		"alg none":       unsigned,
+		"garbage":        "a.b.c",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := a.Authenticate(token)
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestAuthenticate_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwt.pub")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	publicKey, err := LoadRSAPublicKey(path)
+	require.NoError(t, err)
+	a := New(Config{RSAPublicKey: publicKey})
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims()).SignedString(key)
+	require.NoError(t, err)
+	p, err := a.Authenticate(token)
+	require.NoError(t, err)
+	assert.Equal(t, "svc-billing", p.Subject)
+
+	// HS256 с публичным ключом в роли секрета - классическая подмена алгоритма.
+	_, err = a.Authenticate(signHS256(t, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys("billing:k1:orders:read orders:write; ops:k2:admin; crm:k3:orders:read role:support")
+	require.NoError(t, err)
+	assert.Equal(t, Principal{Subject: "billing", Scopes: []Scope{ScopeOrdersRead, ScopeOrdersWrite}, Role: RoleAnalytics}, keys["k1"])
+	assert.Equal(t, Principal{Subject: "ops", Scopes: []Scope{ScopeAdmin}, Role: RoleAdmin}, keys["k2"])
+	assert.Equal(t, Principal{Subject: "crm", Scopes: []Scope{ScopeOrdersRead}, Role: RoleSupport}, keys["k3"])
+
+	for _, spec := range []string{"billing:k1", "billing:k1:", ":k1:admin", "crm:k3:role:support", "crm:k3:orders:read role:root"} {
+		_, err := ParseAPIKeys(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -2,7 +2,10 @@ package cache
 
 import (
 	"hash/fnv"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"l0/internal/models"
 )
@@ -10,55 +13,289 @@ import (
 type Cache interface {
 	Set(uid string, order models.Order)
 	Get(uid string) (models.Order, bool)
+	// Peek читает заказ, не учитывая обращение в статистике попаданий.
+	Peek(uid string) (models.Order, bool)
 	GetAll() map[string]models.Order
 	Remove(uid string)
+	// Lookup возвращает заказы по вторичному индексу. ok == false означает,
+	// что кэш не знает полный набор заказов по ключу и нужно идти в БД.
+	Lookup(kind IndexKind, key string) ([]models.Order, bool)
+	// StoreLookup кладёт в кэш полный результат поиска по ключу из БД.
+	StoreLookup(kind IndexKind, key string, orders []models.Order)
+	// Stats возвращает размер кэша и статистику попаданий Get.
+	Stats() Stats
+	// Clear удаляет все заказы и возвращает, сколько их было.
+	Clear() int
+	// MarkMissing запоминает на короткое время, что заказа нет в БД.
+	// Запись заказа через Set снимает отметку.
+	MarkMissing(uid string)
+	// Missing сообщает, что заказа недавно не было в БД и обращаться к ней не нужно.
+	Missing(uid string) bool
+}
+
+// Stats - снимок состояния кэша для админского API.
+type Stats struct {
+	Orders int `json:"orders"`
+	// Bytes - приблизительный объём заказов в памяти (см. orderSize).
+	Bytes int64 `json:"bytes"`
+	// Shards - число заказов в каждом шарде: перекос показывает неудачное распределение ключей.
+	Shards   []int   `json:"shards"`
+	Hits     uint64  `json:"hits"`
+	Misses   uint64  `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+	// Evictions - заказы, вытесненные из-за лимитов, Expirations - удалённые по TTL,
+	// Rejections - новые заказы, которые политика LFU не пустила в заполненный кэш.
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	Rejections  uint64 `json:"rejections"`
+	// Missing - сколько UID помечено как отсутствующие в БД, NegativeHits - сколько
+	// запросов таких UID обошлось без обращения к БД.
+	Missing      int    `json:"missing"`
+	NegativeHits uint64 `json:"negative_hits"`
+
+	Policy     Policy  `json:"policy"`
+	MaxEntries int     `json:"max_entries"`
+	MaxBytes   int64   `json:"max_bytes"`
+	TTLSeconds float64 `json:"ttl_seconds"`
+	// NegativeTTLSeconds - сколько помнится отсутствие заказа в БД, 0 - не помнится.
+	NegativeTTLSeconds float64 `json:"negative_ttl_seconds"`
+}
+
+// entry - заказ в шарде. Поля access обновляются атомарно под RLock шарда,
+// поэтому Get не берёт эксклюзивную блокировку.
+type entry struct {
+	order models.Order
+	hash  uint64
+	size  int64
+	// expiresAt - unix-время в наносекундах, 0 - без TTL.
+	expiresAt int64
+	// access - логическое время последнего обращения внутри шарда.
+	access atomic.Uint64
 }
 
 type shard struct {
 	mu     sync.RWMutex
-	orders map[string]models.Order
+	orders map[string]*entry
+	bytes  int64
+	clock  atomic.Uint64
+	// sketch оценивает частоту обращений для политики LFU.
+	sketch *frequencySketch
+	// missing - UID, которых нет в БД, со временем истечения в unix-наносекундах.
+	missing map[string]int64
 }
 
 type ShardedCache struct {
 	shards []shard
+	index  *secondaryIndex
+
+	policy      Policy
+	ttl         time.Duration
+	negativeTTL time.Duration
+	maxEntries  int
+	maxBytes    int64
+	// shardEntries и shardBytes - лимиты одного шарда, 0 - без ограничения.
+	shardEntries int
+	shardBytes   int64
+	now          func() time.Time
+
+	hits, misses                       atomic.Uint64
+	evictions, expirations, rejections atomic.Uint64
+	negativeHits                       atomic.Uint64
 }
 
 const numShards = 32
 
+// NewCache создаёт кэш без ограничений размера и TTL.
 func NewCache() Cache {
-	shards := make([]shard, numShards)
-	for i := range shards {
-		shards[i].orders = make(map[string]models.Order)
+	return New(Config{})
+}
+
+// New создаёт кэш с лимитами cfg. Лимиты делятся поровну между шардами, поэтому
+// соблюдаются приблизительно: вытеснение происходит, когда переполнен шард заказа.
+func New(cfg Config) Cache {
+	if cfg.Policy == "" {
+		cfg.Policy = PolicyLRU
+	}
+	c := &ShardedCache{
+		shards:      make([]shard, numShards),
+		index:       newSecondaryIndex(),
+		policy:      cfg.Policy,
+		ttl:         cfg.TTL,
+		negativeTTL: cfg.NegativeTTL,
+		maxEntries:  cfg.MaxEntries,
+		maxBytes:    cfg.MaxBytes,
+		now:         time.Now,
 	}
-	return &ShardedCache{shards: shards}
+	if cfg.MaxEntries > 0 {
+		c.shardEntries = (cfg.MaxEntries + numShards - 1) / numShards
+	}
+	if cfg.MaxBytes > 0 {
+		c.shardBytes = (cfg.MaxBytes + numShards - 1) / numShards
+	}
+	for i := range c.shards {
+		c.shards[i].orders = make(map[string]*entry)
+		c.shards[i].missing = make(map[string]int64)
+		if c.policy == PolicyLFU {
+			c.shards[i].sketch = newFrequencySketch(c.shardEntries)
+		}
+	}
+	return c
 }
 
-func (c *ShardedCache) getShard(uid string) *shard {
-	h := fnv.New32a()
+func hashKey(uid string) uint64 {
+	h := fnv.New64a()
 	h.Write([]byte(uid))
-	return &c.shards[h.Sum32()%uint32(numShards)]
+	return h.Sum64()
+}
+
+func (c *ShardedCache) getShard(hash uint64) *shard {
+	return &c.shards[hash%uint64(numShards)]
+}
+
+// expired сообщает, истёк ли TTL записи. Без TTL время не запрашивается.
+func (c *ShardedCache) expired(e *entry) bool {
+	return e.expiresAt != 0 && c.now().UnixNano() >= e.expiresAt
+}
+
+// touch отмечает обращение к записи для политики вытеснения.
+func (c *ShardedCache) touch(s *shard, e *entry) {
+	e.access.Store(s.clock.Add(1))
+	if s.sketch != nil {
+		s.sketch.increment(e.hash)
+	}
 }
 
 func (c *ShardedCache) Set(uid string, order models.Order) {
-	s := c.getShard(uid)
+	hash := hashKey(uid)
+	s := c.getShard(hash)
+	e := &entry{order: order, hash: hash, size: orderSize(order)}
+	if c.ttl > 0 {
+		e.expiresAt = c.now().Add(c.ttl).UnixNano()
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.orders[uid] = order
+
+	delete(s.missing, uid)
+	c.touch(s, e)
+	old, exists := s.orders[uid]
+	if !exists && !c.admit(s, e) {
+		c.rejections.Add(1)
+		// Заказ есть в БД, но не в кэше: полные наборы по его ключам больше не полные.
+		c.index.remove(uid, order, true)
+		return
+	}
+	if exists {
+		c.index.remove(uid, old.order, false)
+		s.bytes -= old.size
+	}
+	s.orders[uid] = e
+	s.bytes += e.size
+	c.index.add(uid, order)
+
+	c.evictOverflow(s, uid)
 }
 
 func (c *ShardedCache) Get(uid string) (models.Order, bool) {
-	s := c.getShard(uid)
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	order, ok := s.orders[uid]
+	order, ok := c.read(uid, true)
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
 	return order, ok
 }
 
-func (c *ShardedCache) Remove(uid string) {
-	s := c.getShard(uid)
+func (c *ShardedCache) Peek(uid string) (models.Order, bool) {
+	return c.read(uid, false)
+}
+
+// read читает заказ под RLock шарда. Если запись истекла, она удаляется под
+// эксклюзивной блокировкой уже после чтения.
+func (c *ShardedCache) read(uid string, touch bool) (models.Order, bool) {
+	hash := hashKey(uid)
+	s := c.getShard(hash)
+
+	s.mu.RLock()
+	e, ok := s.orders[uid]
+	expired := ok && c.expired(e)
+	switch {
+	case ok && !expired && touch:
+		c.touch(s, e)
+	case touch && s.sketch != nil:
+		// Промахи тоже учитываются: часто запрашиваемый заказ должен пройти в кэш после загрузки из БД.
+		s.sketch.increment(hash)
+	}
+	s.mu.RUnlock()
+
+	if expired {
+		c.expire(s, uid, e)
+		return models.Order{}, false
+	}
+	if !ok {
+		return models.Order{}, false
+	}
+	return e.order, true
+}
+
+func (c *ShardedCache) expire(s *shard, uid string, e *entry) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	// Пока блокировки не было, заказ могли перезаписать.
+	if s.orders[uid] == e {
+		c.removeLocked(s, uid, e)
+		c.expirations.Add(1)
+	}
+}
+
+// removeLocked удаляет запись из шарда; вызывается под s.mu.Lock.
+func (c *ShardedCache) removeLocked(s *shard, uid string, e *entry) {
+	c.index.remove(uid, e.order, true)
 	delete(s.orders, uid)
+	s.bytes -= e.size
+}
+
+func (c *ShardedCache) Remove(uid string) {
+	s := c.getShard(hashKey(uid))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.orders[uid]; ok {
+		c.removeLocked(s, uid, e)
+	}
+}
+
+func (c *ShardedCache) Lookup(kind IndexKind, key string) ([]models.Order, bool) {
+	uids, ok := c.index.lookup(indexKey{kind: kind, key: key})
+	if !ok {
+		return nil, false
+	}
+
+	orders := make([]models.Order, 0, len(uids))
+	for _, uid := range uids {
+		order, ok := c.Peek(uid)
+		if !ok {
+			// Заказ удалили между чтением индекса и шарда - набор уже неполный.
+			return nil, false
+		}
+		orders = append(orders, order)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].DateCreated.Equal(orders[j].DateCreated) {
+			return orders[i].DateCreated.After(orders[j].DateCreated)
+		}
+		return orders[i].OrderUID > orders[j].OrderUID
+	})
+	return orders, true
+}
+
+func (c *ShardedCache) StoreLookup(kind IndexKind, key string, orders []models.Order) {
+	uids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		c.Set(order.OrderUID, order)
+		uids = append(uids, order.OrderUID)
+	}
+	c.index.markComplete(indexKey{kind: kind, key: key}, uids)
 }
 
 func (c *ShardedCache) GetAll() map[string]models.Order {
@@ -67,11 +304,61 @@ func (c *ShardedCache) GetAll() map[string]models.Order {
 	for i := range c.shards {
 		s := &c.shards[i]
 		s.mu.RLock()
-		for k, v := range s.orders {
-			result[k] = v
+		for k, e := range s.orders {
+			if !c.expired(e) {
+				result[k] = e.order
+			}
 		}
 		s.mu.RUnlock()
 	}
 
 	return result
 }
+
+func (c *ShardedCache) Stats() Stats {
+	stats := Stats{
+		Shards:      make([]int, len(c.shards)),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+		Rejections:  c.rejections.Load(),
+		Policy:      c.policy,
+		MaxEntries:  c.maxEntries,
+		MaxBytes:    c.maxBytes,
+		TTLSeconds:  c.ttl.Seconds(),
+
+		NegativeHits:       c.negativeHits.Load(),
+		NegativeTTLSeconds: c.negativeTTL.Seconds(),
+	}
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.RLock()
+		stats.Shards[i] = len(s.orders)
+		stats.Bytes += s.bytes
+		stats.Missing += len(s.missing)
+		s.mu.RUnlock()
+		stats.Orders += stats.Shards[i]
+	}
+
+	stats.Hits, stats.Misses = c.hits.Load(), c.misses.Load()
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+func (c *ShardedCache) Clear() int {
+	removed := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		for uid, e := range s.orders {
+			c.index.remove(uid, e.order, true)
+		}
+		removed += len(s.orders)
+		s.orders = make(map[string]*entry)
+		s.missing = make(map[string]int64)
+		s.bytes = 0
+		s.mu.Unlock()
+	}
+	return removed
+}
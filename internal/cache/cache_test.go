@@ -101,3 +101,105 @@ func TestMemoryCache_ConcurrentAccess(t *testing.T) {
 
 	assert.True(t, len(cacheService.GetAll()) > 0)
 }
+
+func TestMemoryCache_LookupRequiresCompleteSet(t *testing.T) {
+	cacheService := NewCache()
+
+	order := models.Order{
+		OrderUID:    "idx-1",
+		TrackNumber: "WBIL-1",
+		CustomerID:  "cust-1",
+		Items:       []models.Item{{TrackNumber: "WBIL-ITEM-1"}},
+	}
+	cacheService.Set(order.OrderUID, order)
+
+	_, ok := cacheService.Lookup(ByCustomer, "cust-1")
+	assert.False(t, ok, "set populated only by Set is not known to be complete")
+
+	cacheService.StoreLookup(ByCustomer, "cust-1", []models.Order{order})
+	orders, ok := cacheService.Lookup(ByCustomer, "cust-1")
+	assert.True(t, ok)
+	assert.Len(t, orders, 1)
+
+	newer := models.Order{OrderUID: "idx-2", CustomerID: "cust-1", DateCreated: time.Now()}
+	cacheService.Set(newer.OrderUID, newer)
+	orders, ok = cacheService.Lookup(ByCustomer, "cust-1")
+	assert.True(t, ok, "new orders keep a complete set complete")
+	assert.Equal(t, []string{"idx-2", "idx-1"}, []string{orders[0].OrderUID, orders[1].OrderUID})
+
+	cacheService.Remove("idx-1")
+	_, ok = cacheService.Lookup(ByCustomer, "cust-1")
+	assert.False(t, ok, "removal makes the set incomplete")
+}
+
+func TestMemoryCache_LookupByItemTrackNumber(t *testing.T) {
+	cacheService := NewCache()
+
+	order := models.Order{
+		OrderUID:    "idx-3",
+		TrackNumber: "WBIL-3",
+		Items:       []models.Item{{TrackNumber: "WBIL-ITEM-3"}},
+	}
+	cacheService.StoreLookup(ByTrackNumber, "WBIL-ITEM-3", []models.Order{order})
+
+	orders, ok := cacheService.Lookup(ByTrackNumber, "WBIL-ITEM-3")
+	assert.True(t, ok)
+	assert.Equal(t, "idx-3", orders[0].OrderUID)
+
+	empty := "WBIL-UNKNOWN"
+	cacheService.StoreLookup(ByTrackNumber, empty, nil)
+	orders, ok = cacheService.Lookup(ByTrackNumber, empty)
+	assert.True(t, ok, "empty results are cached as well")
+	assert.Empty(t, orders)
+}
+
+func TestMemoryCache_SetMovesIndexEntries(t *testing.T) {
+	cacheService := NewCache()
+
+	order := models.Order{OrderUID: "idx-4", CustomerID: "old"}
+	cacheService.StoreLookup(ByCustomer, "old", []models.Order{order})
+
+	order.CustomerID = "new"
+	cacheService.Set(order.OrderUID, order)
+
+	orders, ok := cacheService.Lookup(ByCustomer, "old")
+	assert.True(t, ok)
+	assert.Empty(t, orders)
+}
+
+func TestMemoryCache_Stats(t *testing.T) {
+	cacheService := NewCache()
+
+	cacheService.Set("stats-1", models.Order{OrderUID: "stats-1"})
+	cacheService.Set("stats-2", models.Order{OrderUID: "stats-2"})
+	cacheService.Get("stats-1")
+	cacheService.Get("stats-1")
+	cacheService.Get("stats-1")
+	cacheService.Get("missing")
+
+	stats := cacheService.Stats()
+	assert.Equal(t, 2, stats.Orders)
+	assert.Len(t, stats.Shards, numShards)
+	sum := 0
+	for _, n := range stats.Shards {
+		sum += n
+	}
+	assert.Equal(t, 2, sum)
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.InDelta(t, 0.75, stats.HitRatio, 1e-9)
+}
+
+func TestMemoryCache_Clear(t *testing.T) {
+	cacheService := NewCache()
+
+	order := models.Order{OrderUID: "clear-1", CustomerID: "cust-clear"}
+	cacheService.StoreLookup(ByCustomer, "cust-clear", []models.Order{order})
+	cacheService.Set("clear-2", models.Order{OrderUID: "clear-2"})
+
+	assert.Equal(t, 2, cacheService.Clear())
+	assert.Empty(t, cacheService.GetAll())
+	_, ok := cacheService.Lookup(ByCustomer, "cust-clear")
+	assert.False(t, ok, "flush invalidates complete index sets")
+	assert.Equal(t, 0, cacheService.Clear())
+}
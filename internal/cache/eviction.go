@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Policy - политика вытеснения при переполнении шарда.
+type Policy string
+
+const (
+	// PolicyLRU вытесняет заказ, к которому дольше всех не обращались.
+	PolicyLRU Policy = "lru"
+	// PolicyLFU вытесняет редко запрашиваемые заказы и, как TinyLFU, не пускает
+	// в заполненный шард новый заказ, если он запрашивается реже кандидата на вытеснение.
+	PolicyLFU Policy = "lfu"
+)
+
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(strings.ToLower(s)); p {
+	case PolicyLRU, PolicyLFU:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown cache eviction policy %q", s)
+	}
+}
+
+// Config - лимиты кэша. Нулевое значение снимает соответствующее ограничение.
+type Config struct {
+	MaxEntries int
+	// MaxBytes - лимит приблизительного объёма заказов (см. orderSize).
+	MaxBytes int64
+	// TTL - сколько заказ живёт в кэше после последней записи.
+	TTL    time.Duration
+	Policy Policy
+	// NegativeTTL - сколько помнить, что заказа нет в БД (см. MarkMissing); 0 отключает.
+	NegativeTTL time.Duration
+}
+
+// evictionSamples - сколько записей шарда сравнивается при выборе вытесняемой.
+// Выборка вместо точного списка LRU позволяет не менять структуры шарда в Get.
+const evictionSamples = 5
+
+// overflows сообщает, превышает ли шард свои лимиты.
+func (c *ShardedCache) overflows(s *shard) bool {
+	return (c.shardEntries > 0 && len(s.orders) > c.shardEntries) ||
+		(c.shardBytes > 0 && s.bytes > c.shardBytes)
+}
+
+// evictOverflow вытесняет записи, пока шард не уложится в лимиты. Только что
+// записанный заказ keep не вытесняется, даже если сам больше лимита шарда.
+func (c *ShardedCache) evictOverflow(s *shard, keep string) {
+	for c.overflows(s) {
+		uid, victim := c.sampleVictim(s, keep)
+		if victim == nil {
+			return
+		}
+		c.removeLocked(s, uid, victim)
+		if c.expired(victim) {
+			c.expirations.Add(1)
+		} else {
+			c.evictions.Add(1)
+		}
+	}
+}
+
+// admit решает, пускать ли новый заказ в заполненный шард. LRU пускает всегда,
+// LFU - если заказ запрашивается не реже кандидата на вытеснение.
+func (c *ShardedCache) admit(s *shard, candidate *entry) bool {
+	if s.sketch == nil {
+		return true
+	}
+	if !(c.shardEntries > 0 && len(s.orders) >= c.shardEntries) &&
+		!(c.shardBytes > 0 && s.bytes+candidate.size > c.shardBytes) {
+		return true
+	}
+	_, victim := c.sampleVictim(s, "")
+	if victim == nil || c.expired(victim) {
+		return true
+	}
+	return s.sketch.estimate(candidate.hash) >= s.sketch.estimate(victim.hash)
+}
+
+// sampleVictim выбирает запись для вытеснения среди evictionSamples записей шарда:
+// истёкшую, если она попалась, иначе наименее ценную по политике. Порядок обхода
+// map в Go случаен, поэтому выборка не требует отдельного генератора.
+func (c *ShardedCache) sampleVictim(s *shard, keep string) (string, *entry) {
+	var (
+		victimUID string
+		victim    *entry
+		sampled   int
+	)
+	for uid, e := range s.orders {
+		if uid == keep {
+			continue
+		}
+		if c.expired(e) {
+			return uid, e
+		}
+		if victim == nil || c.lessValuable(s, e, victim) {
+			victimUID, victim = uid, e
+		}
+		if sampled++; sampled == evictionSamples {
+			break
+		}
+	}
+	return victimUID, victim
+}
+
+func (c *ShardedCache) lessValuable(s *shard, a, b *entry) bool {
+	if s.sketch != nil {
+		fa, fb := s.sketch.estimate(a.hash), s.sketch.estimate(b.hash)
+		if fa != fb {
+			return fa < fb
+		}
+	}
+	return a.access.Load() < b.access.Load()
+}
+
+// frequencySketch - count-min sketch частот обращений, как в TinyLFU: четыре строки
+// счётчиков до maxFrequency, которые делятся пополам каждые resetAfter обращений,
+// чтобы давно популярные заказы со временем теряли вес.
+type frequencySketch struct {
+	counters   []atomic.Uint32
+	mask       uint64
+	additions  atomic.Uint64
+	resetAfter uint64
+}
+
+const (
+	sketchDepth  = 4
+	maxFrequency = 15
+	// minSketchWidth используется для шардов без лимита числа записей.
+	minSketchWidth = 64
+)
+
+func newFrequencySketch(capacity int) *frequencySketch {
+	width := minSketchWidth
+	if capacity*2 > width {
+		width = 1 << bits.Len(uint(capacity*2-1))
+	}
+	return &frequencySketch{
+		counters:   make([]atomic.Uint32, sketchDepth*width),
+		mask:       uint64(width - 1),
+		resetAfter: uint64(10 * width),
+	}
+}
+
+func (f *frequencySketch) index(hash uint64, row int) int {
+	// У FNV ключей одного шарда совпадают младшие биты, поэтому хеш перемешивается
+	// отдельно для каждой строки (финализатор splitmix64).
+	h := hash ^ uint64(row+1)*0x9e3779b97f4a7c15
+	h = (h ^ h>>30) * 0xbf58476d1ce4e5b9
+	h = (h ^ h>>27) * 0x94d049bb133111eb
+	h ^= h >> 31
+	return row*int(f.mask+1) + int(h&f.mask)
+}
+
+func (f *frequencySketch) increment(hash uint64) {
+	for row := 0; row < sketchDepth; row++ {
+		counter := &f.counters[f.index(hash, row)]
+		if v := counter.Load(); v < maxFrequency {
+			counter.CompareAndSwap(v, v+1)
+		}
+	}
+	if f.additions.Add(1) == f.resetAfter {
+		f.reset()
+	}
+}
+
+func (f *frequencySketch) estimate(hash uint64) uint32 {
+	lowest := uint32(maxFrequency)
+	for row := 0; row < sketchDepth; row++ {
+		if v := f.counters[f.index(hash, row)].Load(); v < lowest {
+			lowest = v
+		}
+	}
+	return lowest
+}
+
+// reset старит частоты. Параллельные increment могут потерять одно-два обращения,
+// для оценки частоты это не важно.
+func (f *frequencySketch) reset() {
+	for i := range f.counters {
+		f.counters[i].Store(f.counters[i].Load() / 2)
+	}
+	f.additions.Store(0)
+}
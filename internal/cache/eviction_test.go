@@ -0,0 +1,222 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"l0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sameShardUIDs подбирает n UID, которые попадают в один шард.
+func sameShardUIDs(n int) []string {
+	var uids []string
+	target := hashKey("shard-0") % numShards
+	for i := 0; len(uids) < n; i++ {
+		uid := fmt.Sprintf("shard-%d", i)
+		if hashKey(uid)%numShards == target {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+func TestBoundedCache_LRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(Config{MaxEntries: 2 * numShards, Policy: PolicyLRU})
+	uids := sameShardUIDs(3)
+
+	c.Set(uids[0], models.Order{OrderUID: uids[0]})
+	c.Set(uids[1], models.Order{OrderUID: uids[1]})
+	_, ok := c.Get(uids[0])
+	require.True(t, ok)
+
+	c.Set(uids[2], models.Order{OrderUID: uids[2]})
+
+	_, ok = c.Peek(uids[1])
+	assert.False(t, ok, "least recently used order is evicted")
+	_, ok = c.Peek(uids[0])
+	assert.True(t, ok)
+	_, ok = c.Peek(uids[2])
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), c.Stats().Evictions)
+}
+
+func TestBoundedCache_LFURejectsColdNewcomers(t *testing.T) {
+	c := New(Config{MaxEntries: 2 * numShards, Policy: PolicyLFU})
+	uids := sameShardUIDs(4)
+
+	c.Set(uids[0], models.Order{OrderUID: uids[0]})
+	c.Set(uids[1], models.Order{OrderUID: uids[1]})
+	for i := 0; i < 5; i++ {
+		c.Get(uids[0])
+		c.Get(uids[1])
+	}
+
+	c.Set(uids[2], models.Order{OrderUID: uids[2]})
+	_, ok := c.Peek(uids[2])
+	assert.False(t, ok, "order requested once does not displace hot orders")
+	assert.Equal(t, uint64(1), c.Stats().Rejections)
+
+	for i := 0; i < 10; i++ {
+		c.Get(uids[3])
+	}
+	c.Set(uids[3], models.Order{OrderUID: uids[3]})
+	_, ok = c.Peek(uids[3])
+	assert.True(t, ok, "frequently missed order is admitted")
+
+	stats := c.Stats()
+	assert.Equal(t, 2, stats.Orders)
+	assert.Equal(t, uint64(1), stats.Evictions)
+}
+
+func TestBoundedCache_LFURejectionInvalidatesIndex(t *testing.T) {
+	c := New(Config{MaxEntries: 2 * numShards, Policy: PolicyLFU})
+	uids := sameShardUIDs(3)
+
+	first := models.Order{OrderUID: uids[0], CustomerID: "cust-lfu"}
+	c.StoreLookup(ByCustomer, "cust-lfu", []models.Order{first})
+	c.Set(uids[1], models.Order{OrderUID: uids[1]})
+	for i := 0; i < 5; i++ {
+		c.Get(uids[0])
+		c.Get(uids[1])
+	}
+	_, ok := c.Lookup(ByCustomer, "cust-lfu")
+	require.True(t, ok)
+
+	c.Set(uids[2], models.Order{OrderUID: uids[2], CustomerID: "cust-lfu"})
+	require.Equal(t, uint64(1), c.Stats().Rejections)
+	_, ok = c.Lookup(ByCustomer, "cust-lfu")
+	assert.False(t, ok, "rejected order makes the lookup set incomplete")
+}
+
+func TestBoundedCache_MaxBytes(t *testing.T) {
+	uids := sameShardUIDs(3)
+	orders := make([]models.Order, len(uids))
+	var largest int64
+	for i, uid := range uids {
+		orders[i] = models.Order{OrderUID: uid, Items: make([]models.Item, 10)}
+		largest = max(largest, orderSize(orders[i]))
+	}
+	c := New(Config{MaxBytes: 2 * largest * numShards})
+
+	for i, uid := range uids {
+		c.Set(uid, orders[i])
+	}
+
+	stats := c.Stats()
+	assert.Equal(t, 2, stats.Orders)
+	assert.LessOrEqual(t, stats.Bytes, 2*largest)
+	assert.Equal(t, uint64(1), stats.Evictions)
+}
+
+func TestBoundedCache_TTL(t *testing.T) {
+	c := New(Config{TTL: time.Minute}).(*ShardedCache)
+	var now atomic.Int64
+	now.Store(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	c.now = func() time.Time { return time.Unix(0, now.Load()) }
+
+	order := models.Order{OrderUID: "ttl-1", CustomerID: "cust-ttl"}
+	c.StoreLookup(ByCustomer, "cust-ttl", []models.Order{order})
+
+	now.Add(int64(30 * time.Second))
+	_, ok := c.Get("ttl-1")
+	assert.True(t, ok)
+
+	now.Add(int64(31 * time.Second))
+	assert.Empty(t, c.GetAll())
+	_, ok = c.Lookup(ByCustomer, "cust-ttl")
+	assert.False(t, ok, "expired order makes the lookup set incomplete")
+	_, ok = c.Get("ttl-1")
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, 0, stats.Orders)
+	assert.Equal(t, int64(0), stats.Bytes)
+	assert.Equal(t, uint64(1), stats.Expirations)
+	assert.Equal(t, 60.0, stats.TTLSeconds)
+}
+
+func TestBoundedCache_EvictionInvalidatesIndex(t *testing.T) {
+	c := New(Config{MaxEntries: numShards})
+	uids := sameShardUIDs(2)
+
+	first := models.Order{OrderUID: uids[0], CustomerID: "cust-evict"}
+	c.StoreLookup(ByCustomer, "cust-evict", []models.Order{first})
+	_, ok := c.Lookup(ByCustomer, "cust-evict")
+	require.True(t, ok)
+
+	c.Set(uids[1], models.Order{OrderUID: uids[1]})
+	_, ok = c.Lookup(ByCustomer, "cust-evict")
+	assert.False(t, ok)
+}
+
+func TestBoundedCache_SetUpdatesSize(t *testing.T) {
+	c := NewCache()
+
+	c.Set("size-1", models.Order{OrderUID: "size-1"})
+	small := c.Stats().Bytes
+	c.Set("size-1", models.Order{OrderUID: "size-1", Items: make([]models.Item, 5)})
+	assert.Greater(t, c.Stats().Bytes, small)
+
+	c.Remove("size-1")
+	assert.Equal(t, int64(0), c.Stats().Bytes)
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("LFU")
+	require.NoError(t, err)
+	assert.Equal(t, PolicyLFU, p)
+
+	_, err = ParsePolicy("fifo")
+	assert.Error(t, err)
+}
+
+func benchmarkGet(b *testing.B, cfg Config) {
+	c := New(cfg)
+	const orders = 10000
+	for i := 0; i < orders; i++ {
+		uid := "bench-" + strconv.Itoa(i)
+		c.Set(uid, models.Order{OrderUID: uid, Items: make([]models.Item, 2)})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.Get("bench-" + strconv.Itoa(i%orders))
+			i++
+		}
+	})
+}
+
+func BenchmarkCacheGet_Unbounded(b *testing.B) {
+	benchmarkGet(b, Config{})
+}
+
+func BenchmarkCacheGet_LRU(b *testing.B) {
+	benchmarkGet(b, Config{MaxEntries: 20000, TTL: time.Hour, Policy: PolicyLRU})
+}
+
+func BenchmarkCacheGet_LFU(b *testing.B) {
+	benchmarkGet(b, Config{MaxEntries: 20000, TTL: time.Hour, Policy: PolicyLFU})
+}
+
+func BenchmarkCacheSet_Evicting(b *testing.B) {
+	for _, policy := range []Policy{PolicyLRU, PolicyLFU} {
+		b.Run(string(policy), func(b *testing.B) {
+			c := New(Config{MaxEntries: 1000, Policy: policy})
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				uid := "bench-" + strconv.Itoa(i)
+				c.Set(uid, models.Order{OrderUID: uid})
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"l0/internal/models"
+)
+
+// IndexKind - вид вторичного индекса кэша.
+type IndexKind int
+
+const (
+	// ByTrackNumber индексирует трек-номер заказа и трек-номера всех его товаров.
+	ByTrackNumber IndexKind = iota
+	// ByCustomer индексирует customer_id.
+	ByCustomer
+)
+
+type indexKey struct {
+	kind IndexKind
+	key  string
+}
+
+// indexEntry хранит UID заказов по ключу. complete означает, что набор был целиком
+// загружен из БД и с тех пор не терял элементов, поэтому ему можно отвечать без БД.
+type indexEntry struct {
+	uids     map[string]struct{}
+	complete bool
+}
+
+type indexShard struct {
+	mu      sync.RWMutex
+	entries map[indexKey]*indexEntry
+}
+
+type secondaryIndex struct {
+	shards []indexShard
+}
+
+func newSecondaryIndex() *secondaryIndex {
+	shards := make([]indexShard, numShards)
+	for i := range shards {
+		shards[i].entries = make(map[indexKey]*indexEntry)
+	}
+	return &secondaryIndex{shards: shards}
+}
+
+func (idx *secondaryIndex) getShard(k indexKey) *indexShard {
+	h := fnv.New32a()
+	h.Write([]byte{byte(k.kind)})
+	h.Write([]byte(k.key))
+	return &idx.shards[h.Sum32()%uint32(numShards)]
+}
+
+func orderIndexKeys(order models.Order) []indexKey {
+	keys := make([]indexKey, 0, len(order.Items)+2)
+	seen := make(map[string]struct{}, len(order.Items)+1)
+
+	addTrack := func(track string) {
+		if track == "" {
+			return
+		}
+		if _, ok := seen[track]; ok {
+			return
+		}
+		seen[track] = struct{}{}
+		keys = append(keys, indexKey{kind: ByTrackNumber, key: track})
+	}
+
+	addTrack(order.TrackNumber)
+	for _, item := range order.Items {
+		addTrack(item.TrackNumber)
+	}
+	if order.CustomerID != "" {
+		keys = append(keys, indexKey{kind: ByCustomer, key: order.CustomerID})
+	}
+	return keys
+}
+
+// add добавляет заказ в индекс, не меняя признак полноты.
+func (idx *secondaryIndex) add(uid string, order models.Order) {
+	for _, k := range orderIndexKeys(order) {
+		s := idx.getShard(k)
+		s.mu.Lock()
+		entry, ok := s.entries[k]
+		if !ok {
+			entry = &indexEntry{uids: make(map[string]struct{})}
+			s.entries[k] = entry
+		}
+		entry.uids[uid] = struct{}{}
+		s.mu.Unlock()
+	}
+}
+
+// remove убирает заказ из индекса. Если invalidate, наборы перестают считаться полными:
+// заказ по-прежнему есть в БД, но больше не лежит в кэше.
+func (idx *secondaryIndex) remove(uid string, order models.Order, invalidate bool) {
+	for _, k := range orderIndexKeys(order) {
+		s := idx.getShard(k)
+		s.mu.Lock()
+		if entry, ok := s.entries[k]; ok {
+			delete(entry.uids, uid)
+			if invalidate {
+				entry.complete = false
+			}
+			if len(entry.uids) == 0 && !entry.complete {
+				delete(s.entries, k)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (idx *secondaryIndex) markComplete(k indexKey, uids []string) {
+	s := idx.getShard(k)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[k]
+	if !ok {
+		entry = &indexEntry{uids: make(map[string]struct{}, len(uids))}
+		s.entries[k] = entry
+	}
+	for _, uid := range uids {
+		entry.uids[uid] = struct{}{}
+	}
+	entry.complete = true
+}
+
+func (idx *secondaryIndex) lookup(k indexKey) ([]string, bool) {
+	s := idx.getShard(k)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[k]
+	if !ok || !entry.complete {
+		return nil, false
+	}
+
+	uids := make([]string, 0, len(entry.uids))
+	for uid := range entry.uids {
+		uids = append(uids, uid)
+	}
+	return uids, true
+}
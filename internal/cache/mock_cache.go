@@ -35,6 +35,20 @@ func (m *MockCache) EXPECT() *MockCacheMockRecorder {
 	return m.recorder
 }
 
+// Clear mocks base method.
+func (m *MockCache) Clear() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clear")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockCacheMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockCache)(nil).Clear))
+}
+
 // Get mocks base method.
 func (m *MockCache) Get(uid string) (models.Order, bool) {
 	m.ctrl.T.Helper()
@@ -64,6 +78,74 @@ func (mr *MockCacheMockRecorder) GetAll() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCache)(nil).GetAll))
 }
 
+// Lookup mocks base method.
+func (m *MockCache) Lookup(kind IndexKind, key string) ([]models.Order, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", kind, key)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Lookup indicates an expected call of Lookup.
+func (mr *MockCacheMockRecorder) Lookup(kind, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockCache)(nil).Lookup), kind, key)
+}
+
+// MarkMissing mocks base method.
+func (m *MockCache) MarkMissing(uid string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MarkMissing", uid)
+}
+
+// MarkMissing indicates an expected call of MarkMissing.
+func (mr *MockCacheMockRecorder) MarkMissing(uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkMissing", reflect.TypeOf((*MockCache)(nil).MarkMissing), uid)
+}
+
+// Missing mocks base method.
+func (m *MockCache) Missing(uid string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Missing", uid)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Missing indicates an expected call of Missing.
+func (mr *MockCacheMockRecorder) Missing(uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Missing", reflect.TypeOf((*MockCache)(nil).Missing), uid)
+}
+
+// Peek mocks base method.
+func (m *MockCache) Peek(uid string) (models.Order, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Peek", uid)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Peek indicates an expected call of Peek.
+func (mr *MockCacheMockRecorder) Peek(uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peek", reflect.TypeOf((*MockCache)(nil).Peek), uid)
+}
+
+// Remove mocks base method.
+func (m *MockCache) Remove(uid string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Remove", uid)
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockCacheMockRecorder) Remove(uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockCache)(nil).Remove), uid)
+}
+
 // Set mocks base method.
 func (m *MockCache) Set(uid string, order models.Order) {
 	m.ctrl.T.Helper()
@@ -75,3 +157,29 @@ func (mr *MockCacheMockRecorder) Set(uid, order interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCache)(nil).Set), uid, order)
 }
+
+// Stats mocks base method.
+func (m *MockCache) Stats() Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockCacheMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockCache)(nil).Stats))
+}
+
+// StoreLookup mocks base method.
+func (m *MockCache) StoreLookup(kind IndexKind, key string, orders []models.Order) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StoreLookup", kind, key, orders)
+}
+
+// StoreLookup indicates an expected call of StoreLookup.
+func (mr *MockCacheMockRecorder) StoreLookup(kind, key, orders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreLookup", reflect.TypeOf((*MockCache)(nil).StoreLookup), kind, key, orders)
+}
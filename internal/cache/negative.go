@@ -0,0 +1,53 @@
+package cache
+
+// maxMissingPerShard ограничивает отметки об отсутствующих заказах: перебор
+// случайных UID не должен раздувать кэш.
+const maxMissingPerShard = 1024
+
+func (c *ShardedCache) MarkMissing(uid string) {
+	if c.negativeTTL <= 0 {
+		return
+	}
+	s := c.getShard(hashKey(uid))
+	now := c.now().UnixNano()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.orders[uid]; ok {
+		// Заказ успел прийти из Kafka, пока шёл запрос к БД.
+		return
+	}
+	if len(s.missing) >= maxMissingPerShard {
+		for k, expiresAt := range s.missing {
+			if expiresAt <= now {
+				delete(s.missing, k)
+			}
+		}
+	}
+	if len(s.missing) >= maxMissingPerShard {
+		// Все отметки свежие: вытесняется произвольная, порядок обхода map случаен.
+		for k := range s.missing {
+			delete(s.missing, k)
+			break
+		}
+	}
+	s.missing[uid] = now + int64(c.negativeTTL)
+}
+
+func (c *ShardedCache) Missing(uid string) bool {
+	if c.negativeTTL <= 0 {
+		return false
+	}
+	s := c.getShard(hashKey(uid))
+
+	s.mu.RLock()
+	expiresAt, ok := s.missing[uid]
+	s.mu.RUnlock()
+
+	if !ok || c.now().UnixNano() >= expiresAt {
+		return false
+	}
+	c.negativeHits.Add(1)
+	return true
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"l0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegativeCache_MarkMissing(t *testing.T) {
+	c := New(Config{NegativeTTL: time.Minute}).(*ShardedCache)
+	var now atomic.Int64
+	now.Store(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	c.now = func() time.Time { return time.Unix(0, now.Load()) }
+
+	assert.False(t, c.Missing("bogus"))
+	c.MarkMissing("bogus")
+	assert.True(t, c.Missing("bogus"))
+
+	stats := c.Stats()
+	assert.Equal(t, 1, stats.Missing)
+	assert.Equal(t, uint64(1), stats.NegativeHits)
+	assert.Equal(t, 60.0, stats.NegativeTTLSeconds)
+
+	now.Add(int64(time.Minute))
+	assert.False(t, c.Missing("bogus"), "mark expires after NegativeTTL")
+}
+
+func TestNegativeCache_SetClearsMark(t *testing.T) {
+	c := New(Config{NegativeTTL: time.Minute})
+
+	c.MarkMissing("late-1")
+	c.Set("late-1", models.Order{OrderUID: "late-1"})
+	assert.False(t, c.Missing("late-1"))
+
+	c.MarkMissing("late-1")
+	assert.False(t, c.Missing("late-1"), "cached order is never marked missing")
+
+	c.MarkMissing("late-2")
+	c.Clear()
+	assert.False(t, c.Missing("late-2"))
+}
+
+func TestNegativeCache_Disabled(t *testing.T) {
+	c := NewCache()
+
+	c.MarkMissing("bogus")
+	assert.False(t, c.Missing("bogus"))
+	assert.Equal(t, 0, c.Stats().Missing)
+}
+
+func TestNegativeCache_Bounded(t *testing.T) {
+	c := New(Config{NegativeTTL: time.Minute})
+
+	for i := 0; i < 2*maxMissingPerShard*numShards; i++ {
+		c.MarkMissing(fmt.Sprintf("bogus-%d", i))
+	}
+	assert.LessOrEqual(t, c.Stats().Missing, maxMissingPerShard*numShards)
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"unsafe"
+
+	"l0/internal/models"
+)
+
+// entryOverhead - приблизительные накладные расходы на запись: ключ в map шарда,
+// entry и ссылки во вторичном индексе.
+const entryOverhead = 256
+
+// orderSize приблизительно оценивает память, которую занимает заказ в кэше:
+// структуры и содержимое строк. Точный учёт не нужен - лимит в байтах защищает
+// от неограниченного роста, а не считает память процесса.
+func orderSize(order models.Order) int64 {
+	size := int64(unsafe.Sizeof(order)) + entryOverhead + 2*int64(len(order.OrderUID))
+	size += strLen(order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature,
+		order.CustomerID, order.DeliveryService, order.ShardKey, order.OOFShard, order.Status)
+
+	d := order.Delivery
+	size += strLen(d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Email)
+
+	p := order.Payment
+	size += strLen(p.Transaction, p.RequestID, p.Currency, p.Provider, p.Bank)
+
+	size += int64(cap(order.Items)) * int64(unsafe.Sizeof(models.Item{}))
+	for _, item := range order.Items {
+		size += strLen(item.TrackNumber, item.RID, item.Name, item.Size, item.Brand)
+	}
+	return size
+}
+
+func strLen(values ...string) int64 {
+	var n int64
+	for _, v := range values {
+		n += int64(len(v))
+	}
+	return n
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"time"
+
+	"l0/internal/models"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrSnapshotCorrupted возвращается, если файл снимка повреждён или записан
+// несовместимой версией формата.
+var ErrSnapshotCorrupted = errors.New("cache snapshot is corrupted")
+
+// Snapshot - содержимое кэша на момент TakenAt.
+type Snapshot struct {
+	TakenAt time.Time
+	Orders  []models.Order
+}
+
+// Файл снимка: snapshotMagic, версия формата, сжатый zstd поток gob со Snapshot
+// и в конце CRC-32C всего предыдущего содержимого.
+const (
+	snapshotMagic   = "L0CS"
+	snapshotVersion = 1
+	snapshotHeader  = len(snapshotMagic) + 2
+	snapshotFooter  = 4
+)
+
+var snapshotCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// TakeSnapshot копирует заказы из кэша. Истёкшие записи в снимок не попадают.
+func TakeSnapshot(c Cache, takenAt time.Time) Snapshot {
+	all := c.GetAll()
+	snap := Snapshot{TakenAt: takenAt, Orders: make([]models.Order, 0, len(all))}
+	for _, order := range all {
+		snap.Orders = append(snap.Orders, order)
+	}
+	return snap
+}
+
+// WriteSnapshot сохраняет снимок в path. Файл пишется рядом под временным именем
+// и переименовывается, поэтому прерванная запись не портит предыдущий снимок.
+func WriteSnapshot(path string, snap Snapshot) error {
+	var buf bytes.Buffer
+	buf.WriteString(snapshotMagic)
+	_ = binary.Write(&buf, binary.BigEndian, uint16(snapshotVersion))
+
+	zw, err := zstd.NewWriter(&buf)
+	if err != nil {
+		return fmt.Errorf("failed to create zstd writer: %v", err)
+	}
+	if err := gob.NewEncoder(zw).Encode(snap); err != nil {
+		zw.Close()
+		return fmt.Errorf("failed to encode cache snapshot: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress cache snapshot: %v", err)
+	}
+	_ = binary.Write(&buf, binary.BigEndian, crc32.Checksum(buf.Bytes(), snapshotCRC))
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache snapshot file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache snapshot: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync cache snapshot: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close cache snapshot: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace cache snapshot: %v", err)
+	}
+	return nil
+}
+
+// ReadSnapshot читает снимок из path. Если файла нет, возвращается ошибка
+// os.ErrNotExist, если он повреждён - ErrSnapshotCorrupted.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < snapshotHeader+snapshotFooter || string(data[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, fmt.Errorf("%w: not a snapshot file", ErrSnapshotCorrupted)
+	}
+	if v := binary.BigEndian.Uint16(data[len(snapshotMagic):snapshotHeader]); v != snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrSnapshotCorrupted, v)
+	}
+	body, footer := data[:len(data)-snapshotFooter], data[len(data)-snapshotFooter:]
+	if crc32.Checksum(body, snapshotCRC) != binary.BigEndian.Uint32(footer) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrSnapshotCorrupted)
+	}
+
+	zr, err := zstd.NewReader(bytes.NewReader(body[snapshotHeader:]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd reader: %v", err)
+	}
+	defer zr.Close()
+
+	var snap Snapshot
+	if err := gob.NewDecoder(zr).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+	}
+	return &snap, nil
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"l0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	c := NewCache()
+	takenAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c.Set("snap-1", models.Order{OrderUID: "snap-1", Items: []models.Item{{Name: "Item"}}, DateCreated: takenAt})
+	c.Set("snap-2", models.Order{OrderUID: "snap-2", Status: "paid"})
+
+	require.NoError(t, WriteSnapshot(path, TakeSnapshot(c, takenAt)))
+
+	snap, err := ReadSnapshot(path)
+	require.NoError(t, err)
+	assert.True(t, takenAt.Equal(snap.TakenAt))
+	require.Len(t, snap.Orders, 2)
+	restored := map[string]models.Order{}
+	for _, order := range snap.Orders {
+		restored[order.OrderUID] = order
+	}
+	assert.Equal(t, "Item", restored["snap-1"].Items[0].Name)
+	assert.True(t, takenAt.Equal(restored["snap-1"].DateCreated))
+	assert.Equal(t, "paid", restored["snap-2"].Status)
+
+	matches, err := filepath.Glob(path + ".tmp-*")
+	require.NoError(t, err)
+	assert.Empty(t, matches, "temporary file is renamed")
+}
+
+func TestSnapshot_DetectsCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	require.NoError(t, WriteSnapshot(path, Snapshot{
+		TakenAt: time.Now(),
+		Orders:  []models.Order{{OrderUID: "snap-1"}},
+	}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped)/2] ^= 0xff
+	truncated := data[:len(data)-10]
+	for name, content := range map[string][]byte{
+		"flipped byte": flipped,
+		"truncated":    truncated,
+		"foreign file": []byte("not a snapshot at all"),
+		"empty":        nil,
+	} {
+		require.NoError(t, os.WriteFile(path, content, 0o644))
+		_, err := ReadSnapshot(path)
+		assert.ErrorIs(t, err, ErrSnapshotCorrupted, name)
+	}
+
+	_, err = ReadSnapshot(filepath.Join(t.TempDir(), "missing.snap"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clearWarmupEnv сбрасывает CACHE_WARMUP_* окружения, в котором запущены тесты.
+func clearWarmupEnv(t *testing.T) {
+	for _, key := range []string{"CACHE_WARMUP_MODE", "CACHE_WARMUP_LIMIT", "CACHE_WARMUP_SINCE"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoad_CacheWarmup(t *testing.T) {
+	clearWarmupEnv(t)
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, WarmupModeLast, cfg.CacheWarmupMode)
+	assert.Equal(t, 30, cfg.CacheWarmupLimit)
+	assert.Equal(t, 24*time.Hour, cfg.CacheWarmupSince)
+
+	t.Setenv("CACHE_WARMUP_MODE", "Recent")
+	t.Setenv("CACHE_WARMUP_SINCE", "2h")
+	cfg, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, WarmupModeRecent, cfg.CacheWarmupMode)
+	assert.Equal(t, 2*time.Hour, cfg.CacheWarmupSince)
+}
+
+func TestLoad_InvalidCacheWarmup(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "unknown mode", env: map[string]string{"CACHE_WARMUP_MODE": "newest"}, wantErr: "CACHE_WARMUP_MODE"},
+		{name: "last with zero limit", env: map[string]string{"CACHE_WARMUP_MODE": "last", "CACHE_WARMUP_LIMIT": "0"}, wantErr: "CACHE_WARMUP_LIMIT"},
+		{name: "negative limit", env: map[string]string{"CACHE_WARMUP_LIMIT": "-5"}, wantErr: "CACHE_WARMUP_LIMIT"},
+		{name: "limit is not a number", env: map[string]string{"CACHE_WARMUP_LIMIT": "ten"}, wantErr: "CACHE_WARMUP_LIMIT"},
+		{name: "recent with zero since", env: map[string]string{"CACHE_WARMUP_MODE": "recent", "CACHE_WARMUP_SINCE": "0s"}, wantErr: "CACHE_WARMUP_SINCE"},
+		{name: "negative since", env: map[string]string{"CACHE_WARMUP_SINCE": "-1h"}, wantErr: "CACHE_WARMUP_SINCE"},
+		{name: "since without unit", env: map[string]string{"CACHE_WARMUP_SINCE": "24"}, wantErr: "CACHE_WARMUP_SINCE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearWarmupEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, err := Load()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
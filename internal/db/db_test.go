@@ -41,11 +41,12 @@ func TestPostgres_SaveOrder(t *testing.T) {
 
 	mockDB.EXPECT().
 		SaveOrder(ctx, order).
-		Return(nil).
+		Return(true, nil).
 		Times(1)
 
-	err := mockDB.SaveOrder(ctx, order)
+	created, err := mockDB.SaveOrder(ctx, order)
 	assert.NoError(t, err)
+	assert.True(t, created)
 }
 
 func TestPostgres_GetOrder(t *testing.T) {
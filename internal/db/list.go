@@ -0,0 +1,344 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"l0/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type SortField string
+
+const (
+	SortByDate   SortField = "date"
+	SortByAmount SortField = "amount"
+)
+
+// SortFields - поля, по которым можно сортировать заказы.
+var SortFields = []SortField{SortByDate, SortByAmount}
+
+// ParseSort разбирает сортировку вида "date" или "-amount": префикс "-" означает убывание.
+// Пустая строка - сортировка по умолчанию, от новых заказов к старым.
+func ParseSort(value string) (SortField, bool, error) {
+	if value == "" {
+		return SortByDate, true, nil
+	}
+	sort := SortField(strings.TrimPrefix(value, "-"))
+	if !slices.Contains(SortFields, sort) {
+		return sort, false, fmt.Errorf("unknown sort field %q", sort)
+	}
+	return sort, strings.HasPrefix(value, "-"), nil
+}
+
+// OrderFilter - фильтры выборки заказов. Пустые поля не участвуют в запросе.
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	// UpdatedFrom отбирает заказы, созданные или изменённые не раньше указанного времени.
+	UpdatedFrom     time.Time
+	PaymentProvider string
+	Currency        string
+	ItemBrand       string
+}
+
+type ListOrdersParams struct {
+	Filter OrderFilter
+	Sort   SortField
+	Desc   bool
+	Limit  int
+	Cursor string
+}
+
+type OrderPage struct {
+	Orders     []models.Order
+	NextCursor string
+}
+
+// listCursor - позиция последней записи страницы для keyset-пагинации.
+type listCursor struct {
+	Sort      SortField `json:"s"`
+	Desc      bool      `json:"d"`
+	CreatedAt time.Time `json:"t,omitempty"`
+	Amount    int       `json:"a,omitempty"`
+	OrderUID  string    `json:"u"`
+}
+
+func encodeCursor(c listCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string, params ListOrdersParams) (*listCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.OrderUID == "" {
+		return nil, ErrInvalidCursor
+	}
+	// Курсор от другой сортировки дал бы непредсказуемую страницу.
+	if c.Sort != params.Sort || c.Desc != params.Desc {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+func normalizeSort(sort SortField) (SortField, error) {
+	if sort == "" {
+		return SortByDate, nil
+	}
+	if !slices.Contains(SortFields, sort) {
+		return sort, fmt.Errorf("unknown sort field %q", sort)
+	}
+	return sort, nil
+}
+
+func normalizeListParams(params ListOrdersParams) (ListOrdersParams, error) {
+	var err error
+	if params.Sort, err = normalizeSort(params.Sort); err != nil {
+		return params, err
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = DefaultListLimit
+	}
+	if params.Limit > MaxListLimit {
+		params.Limit = MaxListLimit
+	}
+	return params, nil
+}
+
+const orderColumns = `
+			o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature,
+			o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
+			o.status, o.updated_at,
+			d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+			p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt,
+			p.bank, p.delivery_cost, p.goods_total, p.custom_fee`
+
+// buildListOrdersQuery собирает запрос страницы заказов вместе с доставкой и оплатой.
+// Товары подгружаются отдельным запросом, чтобы не размножать строки заказа.
+func buildListOrdersQuery(params ListOrdersParams, cursor *listCursor) (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	f := params.Filter
+	if f.CustomerID != "" {
+		conditions = append(conditions, "o.customer_id = "+arg(f.CustomerID))
+	}
+	if f.TrackNumber != "" {
+		conditions = append(conditions, "o.track_number = "+arg(f.TrackNumber))
+	}
+	if f.DeliveryService != "" {
+		conditions = append(conditions, "o.delivery_service = "+arg(f.DeliveryService))
+	}
+	if !f.CreatedFrom.IsZero() {
+		conditions = append(conditions, "o.date_created >= "+arg(f.CreatedFrom))
+	}
+	if !f.CreatedTo.IsZero() {
+		conditions = append(conditions, "o.date_created < "+arg(f.CreatedTo))
+	}
+	if !f.UpdatedFrom.IsZero() {
+		conditions = append(conditions, "o.updated_at >= "+arg(f.UpdatedFrom))
+	}
+	if f.PaymentProvider != "" {
+		conditions = append(conditions, "p.provider = "+arg(f.PaymentProvider))
+	}
+	if f.Currency != "" {
+		conditions = append(conditions, "p.currency = "+arg(f.Currency))
+	}
+	if f.ItemBrand != "" {
+		conditions = append(conditions,
+			"EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = "+arg(f.ItemBrand)+")")
+	}
+
+	sortColumn := "o.date_created"
+	if params.Sort == SortByAmount {
+		sortColumn = "p.amount"
+	}
+	direction, comparison := "ASC", ">"
+	if params.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if cursor != nil {
+		var value interface{} = cursor.CreatedAt
+		if params.Sort == SortByAmount {
+			value = cursor.Amount
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, o.order_uid) %s (%s, %s)",
+			sortColumn, comparison, arg(value), arg(cursor.OrderUID)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT")
+	sb.WriteString(orderColumns)
+	sb.WriteString(`
+		FROM orders o
+		JOIN delivery d ON d.order_uid = o.order_uid
+		JOIN payment p ON p.order_uid = o.order_uid`)
+	if len(conditions) > 0 {
+		sb.WriteString("\n\t\tWHERE ")
+		sb.WriteString(strings.Join(conditions, "\n\t\t\tAND "))
+	}
+	fmt.Fprintf(&sb, "\n\t\tORDER BY %s %s, o.order_uid %s\n\t\tLIMIT %s",
+		sortColumn, direction, direction, arg(params.Limit+1))
+
+	return sb.String(), args
+}
+
+// ListOrders возвращает страницу заказов по фильтрам с keyset-пагинацией.
+func (p *Postgres) ListOrders(ctx context.Context, params ListOrdersParams) (*OrderPage, error) {
+	params, err := normalizeListParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := decodeCursor(params.Cursor, params)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, next, err := p.listPage(ctx, params, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &OrderPage{Orders: orders}
+	if next != nil {
+		page.NextCursor = encodeCursor(*next)
+	}
+	return page, nil
+}
+
+// listPage загружает до params.Limit заказов после cursor вместе с товарами.
+// Если за страницей есть ещё заказы, возвращается курсор её последней записи.
+func (p *Postgres) listPage(ctx context.Context, params ListOrdersParams, cursor *listCursor) ([]models.Order, *listCursor, error) {
+	query, args := buildListOrdersQuery(params, cursor)
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list orders: %v", err)
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var next *listCursor
+	if len(orders) > params.Limit {
+		orders = orders[:params.Limit]
+		last := orders[len(orders)-1]
+		next = &listCursor{
+			Sort:      params.Sort,
+			Desc:      params.Desc,
+			CreatedAt: last.DateCreated,
+			Amount:    last.Payment.Amount,
+			OrderUID:  last.OrderUID,
+		}
+	}
+
+	if err := p.attachItems(ctx, orders); err != nil {
+		return nil, nil, err
+	}
+	return orders, next, nil
+}
+
+// scanOrders читает строки с колонками orderColumns (без товаров).
+func scanOrders(rows pgx.Rows) ([]models.Order, error) {
+	var orders []models.Order
+	for rows.Next() {
+		var o models.Order
+		err := rows.Scan(
+			&o.OrderUID, &o.TrackNumber, &o.Entry, &o.Locale, &o.InternalSignature,
+			&o.CustomerID, &o.DeliveryService, &o.ShardKey, &o.SMID, &o.DateCreated, &o.OOFShard,
+			&o.Status, &o.UpdatedAt,
+			&o.Delivery.Name, &o.Delivery.Phone, &o.Delivery.Zip, &o.Delivery.City,
+			&o.Delivery.Address, &o.Delivery.Region, &o.Delivery.Email,
+			&o.Payment.Transaction, &o.Payment.RequestID, &o.Payment.Currency, &o.Payment.Provider,
+			&o.Payment.Amount, &o.Payment.PaymentDT, &o.Payment.Bank,
+			&o.Payment.DeliveryCost, &o.Payment.GoodsTotal, &o.Payment.CustomFee,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %v", err)
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+	return orders, nil
+}
+
+// attachItems загружает товары сразу для нескольких заказов одним запросом.
+func (p *Postgres) attachItems(ctx context.Context, orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(orders))
+	uids := make([]string, len(orders))
+	for i, o := range orders {
+		index[o.OrderUID] = i
+		uids[i] = o.OrderUID
+	}
+
+	rows, err := p.pool.Query(ctx, `
+		SELECT
+			order_uid, chrt_id, track_number, price, rid, name, sale,
+			size, total_price, nm_id, brand, status
+		FROM items WHERE order_uid = ANY($1)
+		ORDER BY id`, uids)
+	if err != nil {
+		return fmt.Errorf("failed to get items: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			uid  string
+			item models.Item
+		)
+		err := rows.Scan(
+			&uid, &item.ChrtID, &item.TrackNumber, &item.Price, &item.RID, &item.Name,
+			&item.Sale, &item.Size, &item.TotalPrice, &item.NMID, &item.Brand, &item.Status,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan item: %v", err)
+		}
+		i := index[uid]
+		orders[i].Items = append(orders[i].Items, item)
+	}
+	return rows.Err()
+}
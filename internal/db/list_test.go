@@ -0,0 +1,117 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildListOrdersQuery_Filters(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := ListOrdersParams{
+		Filter: OrderFilter{
+			CustomerID:      "cust-1",
+			DeliveryService: "meest",
+			CreatedFrom:     from,
+			PaymentProvider: "wbpay",
+			ItemBrand:       "Nike",
+		},
+		Sort:  SortByDate,
+		Desc:  true,
+		Limit: 10,
+	}
+
+	query, args := buildListOrdersQuery(params, nil)
+
+	assert.Contains(t, query, "o.customer_id = $1")
+	assert.Contains(t, query, "o.delivery_service = $2")
+	assert.Contains(t, query, "o.date_created >= $3")
+	assert.Contains(t, query, "p.provider = $4")
+	assert.Contains(t, query, "i.brand = $5")
+	assert.Contains(t, query, "ORDER BY o.date_created DESC, o.order_uid DESC")
+	assert.Contains(t, query, "LIMIT $6")
+	assert.NotContains(t, query, "o.track_number =")
+	assert.Equal(t, []interface{}{"cust-1", "meest", from, "wbpay", "Nike", 11}, args)
+}
+
+func TestBuildListOrdersQuery_UpdatedFrom(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := ListOrdersParams{Filter: OrderFilter{UpdatedFrom: since}, Sort: SortByDate, Limit: 10}
+
+	query, args := buildListOrdersQuery(params, nil)
+
+	assert.Contains(t, query, "o.updated_at >= $1")
+	assert.Equal(t, []interface{}{since, 11}, args)
+}
+
+func TestBuildListOrdersQuery_Cursor(t *testing.T) {
+	params := ListOrdersParams{Sort: SortByAmount, Limit: 5}
+	cursor := &listCursor{Sort: SortByAmount, Amount: 700, OrderUID: "uid-7"}
+
+	query, args := buildListOrdersQuery(params, cursor)
+
+	assert.Contains(t, query, "(p.amount, o.order_uid) > ($1, $2)")
+	assert.Contains(t, query, "ORDER BY p.amount ASC, o.order_uid ASC")
+	assert.Equal(t, []interface{}{700, "uid-7", 6}, args)
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	params := ListOrdersParams{Sort: SortByDate, Desc: true}
+	original := listCursor{
+		Sort:      SortByDate,
+		Desc:      true,
+		CreatedAt: time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+		OrderUID:  "uid-1",
+	}
+
+	decoded, err := decodeCursor(encodeCursor(original), params)
+	require.NoError(t, err)
+	assert.Equal(t, original.OrderUID, decoded.OrderUID)
+	assert.True(t, original.CreatedAt.Equal(decoded.CreatedAt))
+}
+
+func TestCursor_Invalid(t *testing.T) {
+	params := ListOrdersParams{Sort: SortByDate}
+
+	_, err := decodeCursor("not base64!", params)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	otherSort := encodeCursor(listCursor{Sort: SortByAmount, OrderUID: "uid-1"})
+	_, err = decodeCursor(otherSort, params)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNormalizeListParams(t *testing.T) {
+	params, err := normalizeListParams(ListOrdersParams{Limit: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, SortByDate, params.Sort)
+	assert.Equal(t, MaxListLimit, params.Limit)
+
+	_, err = normalizeListParams(ListOrdersParams{Sort: "name"})
+	assert.Error(t, err)
+}
+
+func TestParseSort(t *testing.T) {
+	for value, want := range map[string]struct {
+		sort SortField
+		desc bool
+	}{
+		"":        {SortByDate, true},
+		"date":    {SortByDate, false},
+		"-date":   {SortByDate, true},
+		"amount":  {SortByAmount, false},
+		"-amount": {SortByAmount, true},
+	} {
+		sort, desc, err := ParseSort(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want.sort, sort, value)
+		assert.Equal(t, want.desc, desc, value)
+	}
+
+	for _, value := range []string{"name", "-", "--date"} {
+		_, _, err := ParseSort(value)
+		assert.Error(t, err, value)
+	}
+}
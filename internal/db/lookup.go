@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"l0/internal/models"
+)
+
+// MaxLookupResults - сколько заказов отдаёт поиск по трек-номеру или покупателю.
+// Остальные доступны через постраничный список с фильтрами track_number и customer_id.
+const MaxLookupResults = 100
+
+// GetOrders загружает несколько заказов одним запросом. Отсутствующие UID просто не попадают в результат.
+func (p *Postgres) GetOrders(ctx context.Context, orderUIDs []string) ([]models.Order, error) {
+	if len(orderUIDs) == 0 {
+		return nil, nil
+	}
+	return p.findOrders(ctx, "o.order_uid = ANY($1)", 0, orderUIDs)
+}
+
+// FindOrdersByTrackNumber ищет до limit заказов по трек-номеру заказа или любого из его товаров.
+func (p *Postgres) FindOrdersByTrackNumber(ctx context.Context, trackNumber string, limit int) ([]models.Order, error) {
+	return p.findOrders(ctx, `
+		o.order_uid IN (
+			SELECT order_uid FROM orders WHERE track_number = $1
+			UNION
+			SELECT order_uid FROM items WHERE track_number = $1
+		)`, limit, trackNumber)
+}
+
+// FindOrdersByCustomer возвращает до limit заказов покупателя, начиная с самых новых.
+func (p *Postgres) FindOrdersByCustomer(ctx context.Context, customerID string, limit int) ([]models.Order, error) {
+	return p.findOrders(ctx, "o.customer_id = $1", limit, customerID)
+}
+
+// findOrders выбирает заказы по условию where; limit <= 0 означает без ограничения.
+func (p *Postgres) findOrders(ctx context.Context, where string, limit int, args ...interface{}) ([]models.Order, error) {
+	query := `
+		SELECT` + orderColumns + `
+		FROM orders o
+		JOIN delivery d ON d.order_uid = o.order_uid
+		JOIN payment p ON p.order_uid = o.order_uid
+		WHERE ` + where + `
+		ORDER BY o.date_created DESC, o.order_uid DESC`
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find orders: %v", err)
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.attachItems(ctx, orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_orders_date_created ON orders (date_created, order_uid);
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders (customer_id, date_created);
+CREATE INDEX IF NOT EXISTS idx_orders_track_number ON orders (track_number);
+CREATE INDEX IF NOT EXISTS idx_orders_delivery_service ON orders (delivery_service, date_created);
+CREATE INDEX IF NOT EXISTS idx_payment_amount ON payment (amount, order_uid);
+CREATE INDEX IF NOT EXISTS idx_payment_provider_currency ON payment (provider, currency);
+CREATE INDEX IF NOT EXISTS idx_items_order_uid ON items (order_uid);
+CREATE INDEX IF NOT EXISTS idx_items_brand ON items (brand, order_uid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_items_brand;
+DROP INDEX IF EXISTS idx_items_order_uid;
+DROP INDEX IF EXISTS idx_payment_provider_currency;
+DROP INDEX IF EXISTS idx_payment_amount;
+DROP INDEX IF EXISTS idx_orders_delivery_service;
+DROP INDEX IF EXISTS idx_orders_track_number;
+DROP INDEX IF EXISTS idx_orders_customer_id;
+DROP INDEX IF EXISTS idx_orders_date_created;
+-- +goose StatementEnd
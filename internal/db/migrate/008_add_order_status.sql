@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN status VARCHAR NOT NULL DEFAULT 'created';
+ALTER TABLE orders ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE;
+UPDATE orders SET updated_at = date_created;
+ALTER TABLE orders ALTER COLUMN updated_at SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN updated_at SET DEFAULT now();
+
+CREATE TABLE order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_uid VARCHAR NOT NULL REFERENCES orders(order_uid) ON DELETE CASCADE,
+    from_status VARCHAR,
+    to_status VARCHAR NOT NULL,
+    reason VARCHAR,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_order_status_history_order_uid ON order_status_history (order_uid, changed_at);
+
+INSERT INTO order_status_history (order_uid, to_status, changed_at)
+SELECT order_uid, status, date_created FROM orders;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_status_history;
+ALTER TABLE orders DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -8,10 +8,10 @@ import (
 	context "context"
 	reflect "reflect"
 
+	domain "l0/internal/domain"
 	models "l0/internal/models"
 
 	gomock "github.com/golang/mock/gomock"
-	pgxpool "github.com/jackc/pgx/v5/pgxpool"
 )
 
 // MockDatabase is a mock of Database interface.
@@ -49,6 +49,36 @@ func (mr *MockDatabaseMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockDatabase)(nil).Close))
 }
 
+// FindOrdersByCustomer mocks base method.
+func (m *MockDatabase) FindOrdersByCustomer(ctx context.Context, customerID string, limit int) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrdersByCustomer", ctx, customerID, limit)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrdersByCustomer indicates an expected call of FindOrdersByCustomer.
+func (mr *MockDatabaseMockRecorder) FindOrdersByCustomer(ctx, customerID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrdersByCustomer", reflect.TypeOf((*MockDatabase)(nil).FindOrdersByCustomer), ctx, customerID, limit)
+}
+
+// FindOrdersByTrackNumber mocks base method.
+func (m *MockDatabase) FindOrdersByTrackNumber(ctx context.Context, trackNumber string, limit int) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrdersByTrackNumber", ctx, trackNumber, limit)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrdersByTrackNumber indicates an expected call of FindOrdersByTrackNumber.
+func (mr *MockDatabaseMockRecorder) FindOrdersByTrackNumber(ctx, trackNumber, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrdersByTrackNumber", reflect.TypeOf((*MockDatabase)(nil).FindOrdersByTrackNumber), ctx, trackNumber, limit)
+}
+
 // GetOrder mocks base method.
 func (m *MockDatabase) GetOrder(ctx context.Context, orderUID string) (*models.Order, error) {
 	m.ctrl.T.Helper()
@@ -64,26 +94,72 @@ func (mr *MockDatabaseMockRecorder) GetOrder(ctx, orderUID interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockDatabase)(nil).GetOrder), ctx, orderUID)
 }
 
-// GetPool mocks base method.
-func (m *MockDatabase) GetPool() *pgxpool.Pool {
+// GetOrders mocks base method.
+func (m *MockDatabase) GetOrders(ctx context.Context, orderUIDs []string) ([]models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPool")
-	ret0, _ := ret[0].(*pgxpool.Pool)
+	ret := m.ctrl.Call(m, "GetOrders", ctx, orderUIDs)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrders indicates an expected call of GetOrders.
+func (mr *MockDatabaseMockRecorder) GetOrders(ctx, orderUIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockDatabase)(nil).GetOrders), ctx, orderUIDs)
+}
+
+// GetStatusHistory mocks base method.
+func (m *MockDatabase) GetStatusHistory(ctx context.Context, orderUID string) ([]models.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatusHistory", ctx, orderUID)
+	ret0, _ := ret[0].([]models.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatusHistory indicates an expected call of GetStatusHistory.
+func (mr *MockDatabaseMockRecorder) GetStatusHistory(ctx, orderUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatusHistory", reflect.TypeOf((*MockDatabase)(nil).GetStatusHistory), ctx, orderUID)
+}
+
+// ListOrders mocks base method.
+func (m *MockDatabase) ListOrders(ctx context.Context, params ListOrdersParams) (*OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, params)
+	ret0, _ := ret[0].(*OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockDatabaseMockRecorder) ListOrders(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockDatabase)(nil).ListOrders), ctx, params)
+}
+
+// Ping mocks base method.
+func (m *MockDatabase) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// GetPool indicates an expected call of GetPool.
-func (mr *MockDatabaseMockRecorder) GetPool() *gomock.Call {
+// Ping indicates an expected call of Ping.
+func (mr *MockDatabaseMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPool", reflect.TypeOf((*MockDatabase)(nil).GetPool))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockDatabase)(nil).Ping), ctx)
 }
 
 // SaveOrder mocks base method.
-func (m *MockDatabase) SaveOrder(ctx context.Context, order models.Order) error {
+func (m *MockDatabase) SaveOrder(ctx context.Context, order models.Order) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SaveOrder", ctx, order)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // SaveOrder indicates an expected call of SaveOrder.
@@ -91,3 +167,47 @@ func (mr *MockDatabaseMockRecorder) SaveOrder(ctx, order interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrder", reflect.TypeOf((*MockDatabase)(nil).SaveOrder), ctx, order)
 }
+
+// SaveOrders mocks base method.
+func (m *MockDatabase) SaveOrders(ctx context.Context, orders []models.Order) ([]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrders", ctx, orders)
+	ret0, _ := ret[0].([]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveOrders indicates an expected call of SaveOrders.
+func (mr *MockDatabaseMockRecorder) SaveOrders(ctx, orders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrders", reflect.TypeOf((*MockDatabase)(nil).SaveOrders), ctx, orders)
+}
+
+// StreamOrders mocks base method.
+func (m *MockDatabase) StreamOrders(ctx context.Context, params ListOrdersParams, fn func(models.Order) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamOrders", ctx, params, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamOrders indicates an expected call of StreamOrders.
+func (mr *MockDatabaseMockRecorder) StreamOrders(ctx, params, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamOrders", reflect.TypeOf((*MockDatabase)(nil).StreamOrders), ctx, params, fn)
+}
+
+// UpdateOrderStatus mocks base method.
+func (m *MockDatabase) UpdateOrderStatus(ctx context.Context, orderUID string, to domain.OrderStatus, reason string) (*models.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", ctx, orderUID, to, reason)
+	ret0, _ := ret[0].(*models.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
+func (mr *MockDatabaseMockRecorder) UpdateOrderStatus(ctx, orderUID, to, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockDatabase)(nil).UpdateOrderStatus), ctx, orderUID, to, reason)
+}
@@ -2,9 +2,12 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"time"
 
+	"l0/internal/domain"
+	"l0/internal/logging"
 	"l0/internal/models"
 
 	"github.com/jackc/pgx/v5"
@@ -12,10 +15,18 @@ import (
 )
 
 type Database interface {
-	SaveOrder(ctx context.Context, order models.Order) error
+	SaveOrder(ctx context.Context, order models.Order) (bool, error)
+	SaveOrders(ctx context.Context, orders []models.Order) ([]bool, error)
 	GetOrder(ctx context.Context, orderUID string) (*models.Order, error)
+	GetOrders(ctx context.Context, orderUIDs []string) ([]models.Order, error)
+	ListOrders(ctx context.Context, params ListOrdersParams) (*OrderPage, error)
+	StreamOrders(ctx context.Context, params ListOrdersParams, fn func(models.Order) error) error
+	FindOrdersByTrackNumber(ctx context.Context, trackNumber string, limit int) ([]models.Order, error)
+	FindOrdersByCustomer(ctx context.Context, customerID string, limit int) ([]models.Order, error)
+	UpdateOrderStatus(ctx context.Context, orderUID string, to domain.OrderStatus, reason string) (*models.StatusChange, error)
+	GetStatusHistory(ctx context.Context, orderUID string) ([]models.StatusChange, error)
+	Ping(ctx context.Context) error
 	Close()
-	GetPool() *pgxpool.Pool
 }
 
 type Postgres struct {
@@ -32,33 +43,85 @@ func NewPostgres(ctx context.Context, connString string) (Database, error) {
 		return nil, fmt.Errorf("postgres ping failed: %v", err)
 	}
 
-	log.Println("Successfully connected to PostgreSQL")
+	logging.FromContext(ctx).Info("Connected to PostgreSQL")
 	return &Postgres{pool: pool}, nil
 }
 
-// SaveOrder сохраняет заказ в БД (включая delivery, payment и items).
-func (p *Postgres) SaveOrder(ctx context.Context, order models.Order) error {
+// SaveOrder сохраняет заказ в БД (включая delivery, payment и items) и сообщает,
+// был ли он создан. false означает, что заказ с таким UID уже есть и не изменился.
+func (p *Postgres) SaveOrder(ctx context.Context, order models.Order) (bool, error) {
+	created, err := p.SaveOrders(ctx, []models.Order{order})
+	if err != nil {
+		return false, err
+	}
+	return created[0], nil
+}
+
+// SaveOrders сохраняет пачку заказов в одной транзакции: либо все, либо ни одного.
+// created[i] сообщает, был ли создан orders[i]. Уже существующие заказы не меняются,
+// поэтому повтор пачки после сбоя не дублирует товары.
+func (p *Postgres) SaveOrders(ctx context.Context, orders []models.Order) ([]bool, error) {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %v", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
 	}
 	defer func() {
 		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
-			log.Printf("Failed to rollback transaction: %v", err)
+			logging.FromContext(ctx).Error("Failed to rollback transaction", "error", err)
 		}
 	}()
 
-	_, err = tx.Exec(ctx, `
+	created := make([]bool, len(orders))
+	for i, order := range orders {
+		if created[i], err = saveOrder(ctx, tx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return created, nil
+}
+
+// saveOrder вставляет заказ в рамках tx. Delivery, payment и items пишутся только
+// вместе с новой строкой orders: у items нет естественного ключа для ON CONFLICT,
+// а строка заказа и его товары фиксируются одной транзакцией.
+func saveOrder(ctx context.Context, tx pgx.Tx, order models.Order) (bool, error) {
+	status := order.Status
+	if status == "" {
+		status = string(domain.StatusCreated)
+	}
+	updatedAt := order.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+
+	tag, err := tx.Exec(ctx, `
 		INSERT INTO orders (
 			order_uid, track_number, entry, locale, internal_signature,
-			customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard,
+			status, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (order_uid) DO NOTHING`,
 		order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature,
 		order.CustomerID, order.DeliveryService, order.ShardKey, order.SMID, order.DateCreated, order.OOFShard,
+		status, updatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert into orders: %v", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_status_history (order_uid, to_status, changed_at)
+		VALUES ($1, $2, $3)`,
+		order.OrderUID, status, updatedAt,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to insert into orders: %v", err)
+		return false, fmt.Errorf("failed to insert into order_status_history: %v", err)
 	}
 
 	_, err = tx.Exec(ctx, `
@@ -70,7 +133,7 @@ func (p *Postgres) SaveOrder(ctx context.Context, order models.Order) error {
 		order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to insert into delivery: %v", err)
+		return false, fmt.Errorf("failed to insert into delivery: %v", err)
 	}
 
 	_, err = tx.Exec(ctx, `
@@ -84,7 +147,7 @@ func (p *Postgres) SaveOrder(ctx context.Context, order models.Order) error {
 		order.Payment.DeliveryCost, order.Payment.GoodsTotal, order.Payment.CustomFee,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to insert into payment: %v", err)
+		return false, fmt.Errorf("failed to insert into payment: %v", err)
 	}
 
 	for _, item := range order.Items {
@@ -97,11 +160,10 @@ func (p *Postgres) SaveOrder(ctx context.Context, order models.Order) error {
 			item.Sale, item.Size, item.TotalPrice, item.NMID, item.Brand, item.Status,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to insert into items: %v", err)
+			return false, fmt.Errorf("failed to insert into items: %v", err)
 		}
 	}
-
-	return tx.Commit(ctx)
+	return true, nil
 }
 
 // GetOrder возвращает заказ по order_uid.
@@ -112,12 +174,17 @@ func (p *Postgres) GetOrder(ctx context.Context, orderUID string) (*models.Order
 	err := p.pool.QueryRow(ctx, `
         SELECT 
             order_uid, track_number, entry, locale, internal_signature,
-            customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
+            customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard,
+            status, updated_at
         FROM orders WHERE order_uid = $1`, orderUID).
 		Scan(
 			&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale, &order.InternalSignature,
 			&order.CustomerID, &order.DeliveryService, &order.ShardKey, &order.SMID, &order.DateCreated, &order.OOFShard,
+			&order.Status, &order.UpdatedAt,
 		)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get order: %v", err)
 	}
@@ -175,10 +242,11 @@ func (p *Postgres) GetOrder(ctx context.Context, orderUID string) (*models.Order
 	return &order, nil
 }
 
-func (p *Postgres) Close() {
-	p.pool.Close()
+// Ping проверяет, что PostgreSQL доступен и отвечает на запросы.
+func (p *Postgres) Ping(ctx context.Context) error {
+	return p.pool.Ping(ctx)
 }
 
-func (p *Postgres) GetPool() *pgxpool.Pool {
-	return p.pool
+func (p *Postgres) Close() {
+	p.pool.Close()
 }
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"l0/internal/domain"
+	"l0/internal/logging"
+	"l0/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrOrderNotFound - заказа нет в БД. Остальные ошибки GetOrder и UpdateOrderStatus
+// означают недоступность или сбой БД.
+var ErrOrderNotFound = errors.New("order not found")
+
+// UpdateOrderStatus переводит заказ в новый статус и пишет запись в историю.
+// Текущий статус блокируется на время транзакции, поэтому конкурирующие
+// переходы проверяются по актуальному состоянию.
+func (p *Postgres) UpdateOrderStatus(ctx context.Context, orderUID string, to domain.OrderStatus, reason string) (*models.StatusChange, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			logging.FromContext(ctx).Error("Failed to rollback transaction", "error", err)
+		}
+	}()
+
+	var current string
+	err = tx.QueryRow(ctx, `SELECT status FROM orders WHERE order_uid = $1 FOR UPDATE`, orderUID).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order status: %v", err)
+	}
+
+	from := domain.OrderStatus(current)
+	if err := domain.ValidateTransition(from, to); err != nil {
+		return nil, err
+	}
+
+	change := &models.StatusChange{
+		From:      current,
+		To:        string(to),
+		Reason:    reason,
+		ChangedAt: time.Now().UTC(),
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE orders SET status = $2, updated_at = $3 WHERE order_uid = $1`,
+		orderUID, change.To, change.ChangedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order status: %v", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_status_history (order_uid, from_status, to_status, reason, changed_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)`,
+		orderUID, change.From, change.To, change.Reason, change.ChangedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert into order_status_history: %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit status change: %v", err)
+	}
+	return change, nil
+}
+
+// GetStatusHistory возвращает историю статусов заказа в хронологическом порядке.
+func (p *Postgres) GetStatusHistory(ctx context.Context, orderUID string) ([]models.StatusChange, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT COALESCE(from_status, ''), to_status, COALESCE(reason, ''), changed_at
+		FROM order_status_history
+		WHERE order_uid = $1
+		ORDER BY changed_at, id`, orderUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history: %v", err)
+	}
+	defer rows.Close()
+
+	history := []models.StatusChange{}
+	for rows.Next() {
+		var change models.StatusChange
+		if err := rows.Scan(&change.From, &change.To, &change.Reason, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status change: %v", err)
+		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %v", err)
+	}
+	return history, nil
+}
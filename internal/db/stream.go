@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+
+	"l0/internal/models"
+)
+
+// StreamPageSize - сколько заказов StreamOrders читает из БД за один запрос.
+const StreamPageSize = 500
+
+// StreamOrders передаёт в fn все заказы, подходящие под фильтр, в порядке сортировки params.
+// Заказы читаются страницами по StreamPageSize, поэтому в памяти держится не больше одной страницы.
+// params.Limit ограничивает общее число заказов (0 - без ограничения), params.Cursor задаёт
+// начальную позицию. Ошибка из fn прерывает обход и возвращается как есть.
+func (p *Postgres) StreamOrders(ctx context.Context, params ListOrdersParams, fn func(models.Order) error) error {
+	var err error
+	if params.Sort, err = normalizeSort(params.Sort); err != nil {
+		return err
+	}
+
+	cursor, err := decodeCursor(params.Cursor, params)
+	if err != nil {
+		return err
+	}
+
+	remaining := params.Limit
+	for {
+		page := params
+		page.Limit = StreamPageSize
+		if remaining > 0 && remaining < StreamPageSize {
+			page.Limit = remaining
+		}
+
+		orders, next, err := p.listPage(ctx, page, cursor)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			if err := fn(order); err != nil {
+				return err
+			}
+		}
+
+		if remaining > 0 {
+			remaining -= len(orders)
+			if remaining <= 0 {
+				return nil
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		cursor = next
+	}
+}
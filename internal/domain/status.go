@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"l0/internal/models"
+)
+
+// OrderStatus - этап жизненного цикла заказа.
+type OrderStatus string
+
+const (
+	StatusCreated   OrderStatus = "created"
+	StatusPaid      OrderStatus = "paid"
+	StatusAssembled OrderStatus = "assembled"
+	StatusShipped   OrderStatus = "shipped"
+	StatusDelivered OrderStatus = "delivered"
+	StatusCancelled OrderStatus = "cancelled"
+	StatusReturned  OrderStatus = "returned"
+)
+
+var ErrUnknownStatus = errors.New("unknown order status")
+
+// transitions - допустимые переходы. Отменить можно только ещё не отправленный заказ,
+// вернуть - отправленный или доставленный. cancelled и returned - конечные статусы.
+var transitions = map[OrderStatus][]OrderStatus{
+	StatusCreated:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusAssembled, StatusCancelled},
+	StatusAssembled: {StatusShipped, StatusCancelled},
+	StatusShipped:   {StatusDelivered, StatusReturned},
+	StatusDelivered: {StatusReturned},
+	StatusCancelled: nil,
+	StatusReturned:  nil,
+}
+
+// TransitionError - попытка перевести заказ в статус, недостижимый из текущего.
+type TransitionError struct {
+	From OrderStatus
+	To   OrderStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("transition from %q to %q is not allowed", e.From, e.To)
+}
+
+// Statuses возвращает все статусы в порядке жизненного цикла.
+func Statuses() []OrderStatus {
+	return []OrderStatus{
+		StatusCreated, StatusPaid, StatusAssembled, StatusShipped,
+		StatusDelivered, StatusCancelled, StatusReturned,
+	}
+}
+
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	status := OrderStatus(s)
+	if _, ok := transitions[status]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownStatus, s)
+	}
+	return status, nil
+}
+
+func (s OrderStatus) AllowedTransitions() []OrderStatus {
+	return append([]OrderStatus(nil), transitions[s]...)
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+func (s OrderStatus) IsTerminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// ValidateTransition проверяет переход по текущему статусу, сохранённому в заказе.
+func ValidateTransition(from, to OrderStatus) error {
+	if _, ok := transitions[from]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownStatus, from)
+	}
+	if _, ok := transitions[to]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownStatus, to)
+	}
+	if !from.CanTransitionTo(to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// InitStatus выставляет начальный статус новому заказу. Статус из входных данных
+// не принимается: жизненный цикл всегда начинается с created.
+func InitStatus(order *models.Order, now time.Time) {
+	order.Status = string(StatusCreated)
+	order.UpdatedAt = now
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"l0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTransition_HappyPath(t *testing.T) {
+	path := []OrderStatus{StatusCreated, StatusPaid, StatusAssembled, StatusShipped, StatusDelivered, StatusReturned}
+	for i := 0; i < len(path)-1; i++ {
+		assert.NoError(t, ValidateTransition(path[i], path[i+1]), "%s -> %s", path[i], path[i+1])
+	}
+}
+
+func TestValidateTransition_Rejected(t *testing.T) {
+	tests := []struct{ from, to OrderStatus }{
+		{StatusCreated, StatusShipped},
+		{StatusShipped, StatusCancelled},
+		{StatusDelivered, StatusPaid},
+		{StatusCancelled, StatusCreated},
+		{StatusReturned, StatusDelivered},
+		{StatusPaid, StatusPaid},
+	}
+
+	for _, tt := range tests {
+		err := ValidateTransition(tt.from, tt.to)
+		var transitionErr *TransitionError
+		require.ErrorAs(t, err, &transitionErr, "%s -> %s", tt.from, tt.to)
+		assert.Equal(t, tt.from, transitionErr.From)
+		assert.Equal(t, tt.to, transitionErr.To)
+	}
+}
+
+func TestValidateTransition_UnknownStatus(t *testing.T) {
+	assert.ErrorIs(t, ValidateTransition("lost", StatusPaid), ErrUnknownStatus)
+	assert.ErrorIs(t, ValidateTransition(StatusCreated, "lost"), ErrUnknownStatus)
+}
+
+func TestParseOrderStatus(t *testing.T) {
+	for _, status := range Statuses() {
+		parsed, err := ParseOrderStatus(string(status))
+		require.NoError(t, err)
+		assert.Equal(t, status, parsed)
+	}
+
+	_, err := ParseOrderStatus("202")
+	assert.ErrorIs(t, err, ErrUnknownStatus)
+}
+
+func TestTerminalStatuses(t *testing.T) {
+	assert.True(t, StatusCancelled.IsTerminal())
+	assert.True(t, StatusReturned.IsTerminal())
+	assert.False(t, StatusShipped.IsTerminal())
+}
+
+func TestInitStatus(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	order := models.Order{Status: string(StatusDelivered)}
+
+	InitStatus(&order, now)
+	assert.Equal(t, string(StatusCreated), order.Status)
+	assert.Equal(t, now, order.UpdatedAt)
+}
+
+func TestStatuses_MatchOrderValidation(t *testing.T) {
+	field, ok := reflect.TypeOf(models.Order{}).FieldByName("Status")
+	require.True(t, ok)
+	_, oneof, ok := strings.Cut(field.Tag.Get("validate"), "oneof=")
+	require.True(t, ok, "models.Order.Status must be validated with oneof")
+
+	var statuses []string
+	for _, status := range Statuses() {
+		statuses = append(statuses, string(status))
+	}
+	assert.Equal(t, statuses, strings.Fields(oneof))
+}
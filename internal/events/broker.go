@@ -0,0 +1,168 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"l0/internal/models"
+)
+
+type Type string
+
+const (
+	OrderCreated       Type = "order.created"
+	OrderStatusChanged Type = "order.status_changed"
+)
+
+const (
+	DefaultReplaySize       = 1024
+	defaultSubscriberBuffer = 64
+)
+
+// Event - уведомление об изменении заказа. ID монотонно растёт и используется как
+// Last-Event-ID при переподключении. Нумерация каждого процесса начинается с текущего
+// времени в микросекундах, поэтому ID после перезапуска больше ID прежнего процесса.
+type Event struct {
+	ID    uint64       `json:"id"`
+	Type  Type         `json:"type"`
+	Order models.Order `json:"order"`
+	At    time.Time    `json:"at"`
+}
+
+// Publisher - источник уведомлений, которым пользуются консьюмер и API.
+type Publisher interface {
+	Publish(eventType Type, order models.Order) Event
+}
+
+// Filter отбирает события для подписчика. nil пропускает все события.
+type Filter func(Event) bool
+
+// Subscription - подписка на события. Канал C закрывается при Close или если
+// подписчик не успевает читать события; в этом случае Dropped возвращает true.
+type Subscription struct {
+	C <-chan Event
+
+	ch      chan Event
+	filter  Filter
+	broker  *Broker
+	dropped bool
+}
+
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s, false)
+}
+
+func (s *Subscription) Dropped() bool {
+	s.broker.mu.RLock()
+	defer s.broker.mu.RUnlock()
+	return s.dropped
+}
+
+// Broker раздаёт события подписчикам внутри процесса и хранит
+// ограниченный буфер последних событий для повторной отправки.
+type Broker struct {
+	mu          sync.RWMutex
+	lastID      uint64
+	replay      []Event
+	replayStart int
+	replaySize  int
+	subscribers map[*Subscription]struct{}
+}
+
+func NewBroker(replaySize int) *Broker {
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	return &Broker{
+		lastID:      uint64(time.Now().UnixMicro()),
+		replay:      make([]Event, 0, replaySize),
+		replaySize:  replaySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *Broker) Publish(eventType Type, order models.Order) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Order: order, At: time.Now().UTC()}
+
+	if len(b.replay) < b.replaySize {
+		b.replay = append(b.replay, event)
+	} else {
+		b.replay[b.replayStart] = event
+		b.replayStart = (b.replayStart + 1) % b.replaySize
+	}
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// Медленный подписчик не должен тормозить консьюмер: отключаем его,
+			// клиент переподключится с Last-Event-ID и получит пропущенное из буфера.
+			b.removeLocked(sub, true)
+		}
+	}
+	return event
+}
+
+// Subscribe подписывает на новые события и возвращает события из буфера после lastEventID.
+// complete == false означает, что часть событий после lastEventID уже вытеснена из буфера
+// или lastEventID выдан другим процессом, и клиенту стоит перечитать данные через API.
+func (b *Broker) Subscribe(lastEventID uint64, filter Filter) (sub *Subscription, missed []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = true
+	if lastEventID > 0 {
+		events := b.bufferedLocked()
+		// ID в буфере идут подряд, первый доступный - lastID-len(events)+1.
+		oldest := b.lastID - uint64(len(events)) + 1
+		if lastEventID > b.lastID || lastEventID+1 < oldest {
+			complete = false
+		}
+		for _, event := range events {
+			if event.ID > lastEventID && (filter == nil || filter(event)) {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	ch := make(chan Event, defaultSubscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, filter: filter, broker: b}
+	b.subscribers[sub] = struct{}{}
+	return sub, missed, complete
+}
+
+// Subscribers возвращает число активных подписок.
+func (b *Broker) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+func (b *Broker) unsubscribe(sub *Subscription, dropped bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.removeLocked(sub, dropped)
+}
+
+func (b *Broker) removeLocked(sub *Subscription, dropped bool) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	sub.dropped = dropped
+	close(sub.ch)
+}
+
+// bufferedLocked возвращает события буфера от старых к новым.
+func (b *Broker) bufferedLocked() []Event {
+	events := make([]Event, 0, len(b.replay))
+	events = append(events, b.replay[b.replayStart:]...)
+	return append(events, b.replay[:b.replayStart]...)
+}
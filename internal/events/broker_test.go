@@ -0,0 +1,118 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"l0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func order(uid, customer string) models.Order {
+	return models.Order{OrderUID: uid, CustomerID: customer}
+}
+
+func TestBroker_PublishToSubscribers(t *testing.T) {
+	broker := NewBroker(10)
+
+	sub, missed, complete := broker.Subscribe(0, nil)
+	defer sub.Close()
+	assert.Empty(t, missed)
+	assert.True(t, complete)
+
+	published := broker.Publish(OrderCreated, order("o-1", "c-1"))
+	received := <-sub.C
+	assert.Equal(t, published.ID, received.ID)
+	assert.Equal(t, "o-1", received.Order.OrderUID)
+	assert.Equal(t, OrderCreated, received.Type)
+}
+
+func TestBroker_Subscribers(t *testing.T) {
+	broker := NewBroker(10)
+	sub, _, _ := broker.Subscribe(0, nil)
+	assert.Equal(t, 1, broker.Subscribers())
+	sub.Close()
+	assert.Equal(t, 0, broker.Subscribers())
+}
+
+func TestBroker_Filter(t *testing.T) {
+	broker := NewBroker(10)
+
+	sub, _, _ := broker.Subscribe(0, func(e Event) bool { return e.Order.CustomerID == "c-2" })
+	defer sub.Close()
+
+	broker.Publish(OrderCreated, order("o-1", "c-1"))
+	broker.Publish(OrderCreated, order("o-2", "c-2"))
+
+	received := <-sub.C
+	assert.Equal(t, "o-2", received.Order.OrderUID)
+	assert.Empty(t, sub.C)
+}
+
+func TestBroker_ReplayAfterLastEventID(t *testing.T) {
+	broker := NewBroker(3)
+	var ids []uint64
+	for _, uid := range []string{"o-1", "o-2", "o-3", "o-4", "o-5"} {
+		ids = append(ids, broker.Publish(OrderCreated, order(uid, "c")).ID)
+	}
+
+	sub, missed, complete := broker.Subscribe(ids[2], nil)
+	sub.Close()
+	assert.True(t, complete)
+	require.Len(t, missed, 2)
+	assert.Equal(t, ids[3:], []uint64{missed[0].ID, missed[1].ID})
+
+	sub, missed, complete = broker.Subscribe(ids[0], nil)
+	sub.Close()
+	assert.False(t, complete, "event o-2 was evicted from the replay buffer")
+	assert.Len(t, missed, 3)
+}
+
+func TestBroker_ReportsGapAfterRestart(t *testing.T) {
+	previous := NewBroker(10)
+	lastSeen := previous.Publish(OrderCreated, order("o-1", "c")).ID
+	previous.Publish(OrderCreated, order("o-2", "c"))
+
+	// Новый процесс ещё ничего не опубликовал: события старого процесса потеряны.
+	time.Sleep(time.Millisecond)
+	restarted := NewBroker(10)
+	sub, missed, complete := restarted.Subscribe(lastSeen, nil)
+	sub.Close()
+	assert.False(t, complete)
+	assert.Empty(t, missed)
+
+	restarted.Publish(OrderCreated, order("o-3", "c"))
+	sub, missed, complete = restarted.Subscribe(lastSeen, nil)
+	sub.Close()
+	assert.False(t, complete)
+	require.Len(t, missed, 1)
+	assert.Greater(t, missed[0].ID, lastSeen, "IDs keep growing across restarts")
+
+	sub, _, complete = restarted.Subscribe(missed[0].ID+100, nil)
+	sub.Close()
+	assert.False(t, complete, "ID from the future is not trusted")
+
+	sub, _, complete = restarted.Subscribe(missed[0].ID, nil)
+	sub.Close()
+	assert.True(t, complete)
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	broker := NewBroker(10)
+	sub, _, _ := broker.Subscribe(0, nil)
+
+	for i := 0; i < defaultSubscriberBuffer+1; i++ {
+		broker.Publish(OrderCreated, order("o", "c"))
+	}
+
+	count := 0
+	for range sub.C {
+		count++
+	}
+	assert.Equal(t, defaultSubscriberBuffer, count)
+	assert.True(t, sub.Dropped())
+
+	sub.Close()
+}
@@ -0,0 +1,207 @@
+// Package export выгружает заказы в табличные и построчные форматы.
+// Все форматы пишут заказы по одному, не накапливая выгрузку в памяти.
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"l0/internal/models"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatNDJSON, FormatXLSX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q", s)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Writer пишет заказы в выгрузку. Close дописывает хвост формата,
+// но не закрывает нижележащий io.Writer.
+type Writer interface {
+	Write(order models.Order) error
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	var (
+		writer Writer
+		err    error
+	)
+	switch format {
+	case FormatCSV:
+		writer, err = newCSVWriter(w)
+	case FormatNDJSON:
+		writer = newNDJSONWriter(w)
+	case FormatXLSX:
+		writer, err = newXLSXWriter(w)
+	default:
+		err = fmt.Errorf("unsupported export format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// column - колонка табличной выгрузки. Доставка и оплата разворачиваются в колонки
+// заказа, каждая позиция заказа даёт отдельную строку; item == nil для заказа без товаров.
+type column struct {
+	name    string
+	numeric bool
+	value   func(o *models.Order, item *models.Item) string
+}
+
+func orderColumn(name string, value func(o *models.Order) string) column {
+	return column{name: name, value: func(o *models.Order, _ *models.Item) string { return value(o) }}
+}
+
+func orderNumber(name string, value func(o *models.Order) int64) column {
+	return column{name: name, numeric: true, value: func(o *models.Order, _ *models.Item) string {
+		return strconv.FormatInt(value(o), 10)
+	}}
+}
+
+func itemColumn(name string, value func(item *models.Item) string) column {
+	return column{name: name, value: func(_ *models.Order, item *models.Item) string {
+		if item == nil {
+			return ""
+		}
+		return value(item)
+	}}
+}
+
+func itemNumber(name string, value func(item *models.Item) int) column {
+	return column{name: name, numeric: true, value: func(_ *models.Order, item *models.Item) string {
+		if item == nil {
+			return ""
+		}
+		return strconv.Itoa(value(item))
+	}}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+var columns = []column{
+	orderColumn("order_uid", func(o *models.Order) string { return o.OrderUID }),
+	orderColumn("track_number", func(o *models.Order) string { return o.TrackNumber }),
+	orderColumn("entry", func(o *models.Order) string { return o.Entry }),
+	orderColumn("locale", func(o *models.Order) string { return o.Locale }),
+	orderColumn("customer_id", func(o *models.Order) string { return o.CustomerID }),
+	orderColumn("delivery_service", func(o *models.Order) string { return o.DeliveryService }),
+	orderColumn("shardkey", func(o *models.Order) string { return o.ShardKey }),
+	orderNumber("sm_id", func(o *models.Order) int64 { return int64(o.SMID) }),
+	orderColumn("date_created", func(o *models.Order) string { return formatTime(o.DateCreated) }),
+	orderColumn("oof_shard", func(o *models.Order) string { return o.OOFShard }),
+	orderColumn("status", func(o *models.Order) string { return o.Status }),
+	orderColumn("updated_at", func(o *models.Order) string { return formatTime(o.UpdatedAt) }),
+
+	orderColumn("delivery_name", func(o *models.Order) string { return o.Delivery.Name }),
+	orderColumn("delivery_phone", func(o *models.Order) string { return o.Delivery.Phone }),
+	orderColumn("delivery_zip", func(o *models.Order) string { return o.Delivery.Zip }),
+	orderColumn("delivery_city", func(o *models.Order) string { return o.Delivery.City }),
+	orderColumn("delivery_address", func(o *models.Order) string { return o.Delivery.Address }),
+	orderColumn("delivery_region", func(o *models.Order) string { return o.Delivery.Region }),
+	orderColumn("delivery_email", func(o *models.Order) string { return o.Delivery.Email }),
+
+	orderColumn("payment_transaction", func(o *models.Order) string { return o.Payment.Transaction }),
+	orderColumn("payment_request_id", func(o *models.Order) string { return o.Payment.RequestID }),
+	orderColumn("payment_currency", func(o *models.Order) string { return o.Payment.Currency }),
+	orderColumn("payment_provider", func(o *models.Order) string { return o.Payment.Provider }),
+	orderNumber("payment_amount", func(o *models.Order) int64 { return int64(o.Payment.Amount) }),
+	orderNumber("payment_dt", func(o *models.Order) int64 { return o.Payment.PaymentDT }),
+	orderColumn("payment_bank", func(o *models.Order) string { return o.Payment.Bank }),
+	orderNumber("payment_delivery_cost", func(o *models.Order) int64 { return int64(o.Payment.DeliveryCost) }),
+	orderNumber("payment_goods_total", func(o *models.Order) int64 { return int64(o.Payment.GoodsTotal) }),
+	orderNumber("payment_custom_fee", func(o *models.Order) int64 { return int64(o.Payment.CustomFee) }),
+
+	itemNumber("item_chrt_id", func(i *models.Item) int { return i.ChrtID }),
+	itemColumn("item_track_number", func(i *models.Item) string { return i.TrackNumber }),
+	itemNumber("item_price", func(i *models.Item) int { return i.Price }),
+	itemColumn("item_rid", func(i *models.Item) string { return i.RID }),
+	itemColumn("item_name", func(i *models.Item) string { return i.Name }),
+	itemNumber("item_sale", func(i *models.Item) int { return i.Sale }),
+	itemColumn("item_size", func(i *models.Item) string { return i.Size }),
+	itemNumber("item_total_price", func(i *models.Item) int { return i.TotalPrice }),
+	itemNumber("item_nm_id", func(i *models.Item) int { return i.NMID }),
+	itemColumn("item_brand", func(i *models.Item) string { return i.Brand }),
+	itemNumber("item_status", func(i *models.Item) int { return i.Status }),
+}
+
+func header() []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// formulaPrefixes - символы, с которых табличные редакторы начинают формулу.
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula добавляет апостроф к тексту, который Excel или LibreOffice
+// при открытии файла выполнили бы как формулу.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// rows разворачивает заказ в строки таблицы: по одной на каждую позицию.
+// Текстовые значения экранируются от выполнения как формулы.
+func rows(order *models.Order, fn func(record []string) error) error {
+	record := make([]string, len(columns))
+	fill := func(item *models.Item) error {
+		for i, c := range columns {
+			record[i] = c.value(order, item)
+			if !c.numeric {
+				record[i] = escapeFormula(record[i])
+			}
+		}
+		return fn(record)
+	}
+
+	if len(order.Items) == 0 {
+		return fill(nil)
+	}
+	for i := range order.Items {
+		if err := fill(&order.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
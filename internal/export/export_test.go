@@ -0,0 +1,195 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"l0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrder(uid string, items int) models.Order {
+	order := models.Order{
+		OrderUID:    uid,
+		TrackNumber: "WBIL" + uid,
+		CustomerID:  "cust",
+		DateCreated: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Delivery:    models.Delivery{Name: "Test User", City: "Moscow", Address: `Lenina 1, "B" & <C>`},
+		Payment:     models.Payment{Transaction: uid, Currency: "RUB", Amount: 1500, DeliveryCost: 500},
+	}
+	for i := 0; i < items; i++ {
+		order.Items = append(order.Items, models.Item{ChrtID: i + 1, Name: "Item", Price: 500, Brand: "Brand"})
+	}
+	return order
+}
+
+func writeAll(t *testing.T, format Format, orders ...models.Order) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+	for _, order := range orders {
+		require.NoError(t, w.Write(order))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func columnIndex(t *testing.T, name string) int {
+	t.Helper()
+	for i, c := range columns {
+		if c.name == name {
+			return i
+		}
+	}
+	t.Fatalf("unknown column %q", name)
+	return -1
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("XLSX")
+	require.NoError(t, err)
+	assert.Equal(t, FormatXLSX, f)
+
+	_, err = ParseFormat("pdf")
+	assert.Error(t, err)
+}
+
+func TestCSV_OneRowPerItem(t *testing.T) {
+	data := writeAll(t, FormatCSV, testOrder("o-1", 2), testOrder("o-2", 0))
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, header(), records[0])
+
+	uid, amount, chrt := columnIndex(t, "order_uid"), columnIndex(t, "payment_amount"), columnIndex(t, "item_chrt_id")
+	assert.Equal(t, []string{"o-1", "1500", "1"}, []string{records[1][uid], records[1][amount], records[1][chrt]})
+	assert.Equal(t, []string{"o-1", "1500", "2"}, []string{records[2][uid], records[2][amount], records[2][chrt]})
+	assert.Equal(t, []string{"o-2", "1500", ""}, []string{records[3][uid], records[3][amount], records[3][chrt]})
+	assert.Equal(t, "2024-01-02T03:04:05Z", records[1][columnIndex(t, "date_created")])
+	assert.Equal(t, `Lenina 1, "B" & <C>`, records[1][columnIndex(t, "delivery_address")])
+}
+
+func TestNDJSON_OneLinePerOrder(t *testing.T) {
+	data := writeAll(t, FormatNDJSON, testOrder("o-1", 2), testOrder("o-2", 1))
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var order models.Order
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &order))
+	assert.Equal(t, "o-1", order.OrderUID)
+	assert.Len(t, order.Items, 2)
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Ref   string `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSX_ValidWorkbook(t *testing.T) {
+	data := writeAll(t, FormatXLSX, testOrder("o-1", 2))
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	parts := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		assert.Contains(t, parts, name)
+	}
+	require.Contains(t, parts, "xl/worksheets/sheet1.xml")
+
+	rc, err := parts["xl/worksheets/sheet1.xml"].Open()
+	require.NoError(t, err)
+	raw, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+
+	var sheet xlsxSheet
+	require.NoError(t, xml.Unmarshal(raw, &sheet))
+	require.Len(t, sheet.Rows, 3)
+	assert.Equal(t, "order_uid", sheet.Rows[0].Cells[0].Inline)
+	assert.Equal(t, "A2", sheet.Rows[1].Cells[0].Ref)
+	assert.Equal(t, "o-1", sheet.Rows[1].Cells[0].Inline)
+
+	var address, amount string
+	for _, c := range sheet.Rows[1].Cells {
+		switch c.Ref {
+		case columnName(columnIndex(t, "delivery_address")) + "2":
+			address = c.Inline
+		case columnName(columnIndex(t, "payment_amount")) + "2":
+			assert.Empty(t, c.Type, "numeric cells must not be strings")
+			amount = c.Value
+		}
+	}
+	assert.Equal(t, `Lenina 1, "B" & <C>`, address)
+	assert.Equal(t, "1500", amount)
+}
+
+func TestEscapeFormula(t *testing.T) {
+	for value, want := range map[string]string{
+		"=HYPERLINK(\"http://x\")": "'=HYPERLINK(\"http://x\")",
+		"+79990000000":             "'+79990000000",
+		"-2+3":                     "'-2+3",
+		"@SUM(A1)":                 "'@SUM(A1)",
+		"\tcmd":                    "'\tcmd",
+		"\rcmd":                    "'\rcmd",
+		"Lenina 1":                 "Lenina 1",
+		"":                         "",
+	} {
+		assert.Equal(t, want, escapeFormula(value), value)
+	}
+}
+
+func TestExport_EscapesFormulas(t *testing.T) {
+	order := testOrder("o-1", 1)
+	order.Delivery.Name = "=cmd|' /C calc'!A0"
+	order.Items[0].Price = -5
+
+	records, err := csv.NewReader(bytes.NewReader(writeAll(t, FormatCSV, order))).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, "'=cmd|' /C calc'!A0", records[1][columnIndex(t, "delivery_name")])
+	assert.Equal(t, "-5", records[1][columnIndex(t, "item_price")], "numbers are not escaped")
+
+	data := writeAll(t, FormatXLSX, order)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	rc, err := zr.Open("xl/worksheets/sheet1.xml")
+	require.NoError(t, err)
+	defer rc.Close()
+	var sheet xlsxSheet
+	require.NoError(t, xml.NewDecoder(rc).Decode(&sheet))
+	var name string
+	for _, c := range sheet.Rows[1].Cells {
+		if c.Ref == columnName(columnIndex(t, "delivery_name"))+"2" {
+			name = c.Inline
+		}
+	}
+	assert.Equal(t, "'=cmd|' /C calc'!A0", name)
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, want, columnName(i))
+	}
+}
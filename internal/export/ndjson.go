@@ -0,0 +1,25 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"l0/internal/models"
+)
+
+// ndjsonWriter пишет каждый заказ целиком отдельной JSON-строкой.
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{enc: json.NewEncoder(w)}
+}
+
+func (nw *ndjsonWriter) Write(order models.Order) error {
+	return nw.enc.Encode(order)
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,76 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"l0/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthInterceptors проверяет учётные данные из метаданных authorization (Bearer) или x-api-key.
+// Все методы OrderService только читают заказы, поэтому требуют scope orders:read.
+func AuthInterceptors(authenticator *auth.Authenticator) []grpc.ServerOption {
+	authorize := func(ctx context.Context) (context.Context, error) {
+		principal, err := authenticator.Authenticate(credentialFromMetadata(ctx))
+		if errors.Is(err, auth.ErrNoCredentials) {
+			return nil, status.Error(codes.Unauthenticated, "authentication required")
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+		}
+		if !principal.HasScope(auth.ScopeOrdersRead) {
+			return nil, status.Errorf(codes.PermissionDenied, "scope %s is required", auth.ScopeOrdersRead)
+		}
+		return auth.WithPrincipal(ctx, principal), nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := authorize(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := authorize(ss.Context())
+			if err != nil {
+				return err
+			}
+			return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+		}),
+	}
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func credentialFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		scheme, credential, _ := strings.Cut(values[0], " ")
+		if strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(credential)
+		}
+		return ""
+	}
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+
+	ordersv1 "l0/api/orders/v1"
+	"l0/internal/auth"
+	"l0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptors(t *testing.T) {
+	authenticator := auth.New(auth.Config{APIKeys: map[string]auth.Principal{
+		"reader-key": {Subject: "reports", Scopes: []auth.Scope{auth.ScopeOrdersRead}},
+		"writer-key": {Subject: "ingest", Scopes: []auth.Scope{auth.ScopeOrdersWrite}},
+	}})
+	client, mockCache, _, _ := newTestClient(t, AuthInterceptors(authenticator)...)
+	req := &ordersv1.GetOrderRequest{OrderUid: "a-1"}
+
+	_, err := client.GetOrder(context.Background(), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "writer-key")
+	_, err = client.GetOrder(ctx, req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	mockCache.EXPECT().Get("a-1").Return(testOrder("a-1"), true)
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer reader-key")
+	order, err := client.GetOrder(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "a-1", order.GetOrderUid())
+
+	stream, err := client.WatchOrders(context.Background(), &ordersv1.WatchOrdersRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGetOrder_RedactsForRole(t *testing.T) {
+	authenticator := auth.New(auth.Config{APIKeys: map[string]auth.Principal{
+		"support-key": {Subject: "crm", Scopes: []auth.Scope{auth.ScopeOrdersRead}, Role: auth.RoleSupport},
+	}})
+	client, mockCache, _, _ := newTestClient(t, AuthInterceptors(authenticator)...)
+
+	order := testOrder("r-1")
+	order.Delivery = models.Delivery{Name: "Test User", Phone: "+79161234567", Address: "Ploshad Mira 15", City: "Moscow"}
+	mockCache.EXPECT().Get("r-1").Return(order, true)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "support-key")
+	resp, err := client.GetOrder(ctx, &ordersv1.GetOrderRequest{OrderUid: "r-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Test User", resp.GetDelivery().GetName())
+	assert.Equal(t, "+7******4567", resp.GetDelivery().GetPhone())
+	assert.Empty(t, resp.GetDelivery().GetAddress())
+	assert.Equal(t, "Moscow", resp.GetDelivery().GetCity())
+}
@@ -0,0 +1,113 @@
+package grpcapi
+
+import (
+	"time"
+
+	ordersv1 "l0/api/orders/v1"
+	"l0/internal/db"
+	"l0/internal/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func orderToProto(o models.Order) *ordersv1.Order {
+	items := make([]*ordersv1.Item, len(o.Items))
+	for i, item := range o.Items {
+		items[i] = &ordersv1.Item{
+			ChrtId:      int64(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       int64(item.Price),
+			Rid:         item.RID,
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  int64(item.TotalPrice),
+			NmId:        int64(item.NMID),
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+		}
+	}
+
+	return &ordersv1.Order{
+		OrderUid:    o.OrderUID,
+		TrackNumber: o.TrackNumber,
+		Entry:       o.Entry,
+		Delivery: &ordersv1.Delivery{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Zip:     o.Delivery.Zip,
+			City:    o.Delivery.City,
+			Address: o.Delivery.Address,
+			Region:  o.Delivery.Region,
+			Email:   o.Delivery.Email,
+		},
+		Payment: &ordersv1.Payment{
+			Transaction:  o.Payment.Transaction,
+			RequestId:    o.Payment.RequestID,
+			Currency:     o.Payment.Currency,
+			Provider:     o.Payment.Provider,
+			Amount:       int64(o.Payment.Amount),
+			PaymentDt:    o.Payment.PaymentDT,
+			Bank:         o.Payment.Bank,
+			DeliveryCost: int64(o.Payment.DeliveryCost),
+			GoodsTotal:   int64(o.Payment.GoodsTotal),
+			CustomFee:    int64(o.Payment.CustomFee),
+		},
+		Items:             items,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerId:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.ShardKey,
+		SmId:              int64(o.SMID),
+		DateCreated:       timestampToProto(o.DateCreated),
+		OofShard:          o.OOFShard,
+		Status:            o.Status,
+		UpdatedAt:         timestampToProto(o.UpdatedAt),
+	}
+}
+
+func ordersToProto(orders []models.Order) []*ordersv1.Order {
+	result := make([]*ordersv1.Order, len(orders))
+	for i, o := range orders {
+		result[i] = orderToProto(o)
+	}
+	return result
+}
+
+func timestampToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timestampFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func listParamsFromProto(req *ordersv1.ListOrdersRequest) db.ListOrdersParams {
+	params := db.ListOrdersParams{
+		Filter: db.OrderFilter{
+			CustomerID:      req.GetCustomerId(),
+			TrackNumber:     req.GetTrackNumber(),
+			DeliveryService: req.GetDeliveryService(),
+			CreatedFrom:     timestampFromProto(req.GetCreatedFrom()),
+			CreatedTo:       timestampFromProto(req.GetCreatedTo()),
+			PaymentProvider: req.GetPaymentProvider(),
+			Currency:        req.GetCurrency(),
+			ItemBrand:       req.GetItemBrand(),
+		},
+		Sort:   db.SortByDate,
+		Desc:   !req.GetAscending(),
+		Limit:  int(req.GetPageSize()),
+		Cursor: req.GetPageToken(),
+	}
+	if req.GetSort() == ordersv1.SortField_SORT_FIELD_AMOUNT {
+		params.Sort = db.SortByAmount
+	}
+	return params
+}
@@ -0,0 +1,56 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+
+	"l0/internal/auth"
+	"l0/internal/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimitInterceptors привязывает к вызову бюджет клиента из общего с HTTP API лимитера.
+// Опции нужно передавать после AuthInterceptors, чтобы бюджет считался по API-ключу, а не по IP.
+func RateLimitInterceptors(limiter *ratelimit.Limiter) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(ratelimit.NewContext(ctx, limiter, clientKey(ctx)), req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx := ratelimit.NewContext(ss.Context(), limiter, clientKey(ss.Context()))
+			return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+		}),
+	}
+}
+
+func clientKey(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return "principal:" + principal.Subject
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "ip:" + host
+	}
+	return "ip:unknown"
+}
+
+// rateLimitStatus превращает исчерпанный бюджет в ResourceExhausted с подсказкой
+// retry-after (в секундах) в trailer'е. Для остальных ошибок возвращает nil.
+func rateLimitStatus(ctx context.Context, err error) error {
+	var limitErr *ratelimit.LimitError
+	if !errors.As(err, &limitErr) {
+		return nil
+	}
+	_ = grpc.SetTrailer(ctx, metadata.Pairs("retry-after", strconv.Itoa(limitErr.RetryAfterSeconds())))
+	return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+}
@@ -0,0 +1,167 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	ordersv1 "l0/api/orders/v1"
+	"l0/internal/auth"
+	"l0/internal/db"
+	"l0/internal/events"
+	"l0/internal/logging"
+	"l0/internal/redact"
+	"l0/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server реализует ordersv1.OrderServiceServer поверх той же логики чтения, что и HTTP API.
+type Server struct {
+	ordersv1.UnimplementedOrderServiceServer
+
+	orders *service.Orders
+	events *events.Broker
+}
+
+func NewServer(orders *service.Orders, broker *events.Broker) *Server {
+	return &Server{orders: orders, events: broker}
+}
+
+// Register создаёт gRPC-сервер с зарегистрированным OrderService.
+func Register(s *Server, opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	ordersv1.RegisterOrderServiceServer(srv, s)
+	return srv
+}
+
+func (s *Server) GetOrder(ctx context.Context, req *ordersv1.GetOrderRequest) (*ordersv1.Order, error) {
+	if req.GetOrderUid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_uid is required")
+	}
+
+	order, err := s.orders.Get(ctx, req.GetOrderUid())
+	if limited := rateLimitStatus(ctx, err); limited != nil {
+		return nil, limited
+	}
+	if errors.Is(err, db.ErrOrderNotFound) {
+		logging.FromContext(ctx).Info("Order not found", "order_uid", req.GetOrderUid())
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to get order", "order_uid", req.GetOrderUid(), "error", err)
+		return nil, status.Error(codes.Unavailable, "failed to load order")
+	}
+	return orderToProto(redact.Order(*order, auth.RoleFromContext(ctx))), nil
+}
+
+func (s *Server) BatchGetOrders(ctx context.Context, req *ordersv1.BatchGetOrdersRequest) (*ordersv1.BatchGetOrdersResponse, error) {
+	if len(req.GetOrderUids()) > service.MaxBatchGet {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d order_uids are allowed", service.MaxBatchGet)
+	}
+
+	orders, notFound, err := s.orders.BatchGet(ctx, req.GetOrderUids())
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, status.FromContextError(ctxErr).Err()
+	}
+	if limited := rateLimitStatus(ctx, err); limited != nil {
+		return nil, limited
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to batch get orders", "count", len(req.GetOrderUids()), "error", err)
+		return nil, status.Error(codes.Unavailable, "failed to load orders")
+	}
+	return &ordersv1.BatchGetOrdersResponse{
+		Orders:   ordersToProto(redact.Orders(orders, auth.RoleFromContext(ctx))),
+		NotFound: notFound,
+	}, nil
+}
+
+func (s *Server) ListOrders(ctx context.Context, req *ordersv1.ListOrdersRequest) (*ordersv1.ListOrdersResponse, error) {
+	if req.GetPageSize() < 0 || req.GetPageSize() > db.MaxListLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 0 and %d", db.MaxListLimit)
+	}
+
+	page, err := s.orders.List(ctx, listParamsFromProto(req))
+	if limited := rateLimitStatus(ctx, err); limited != nil {
+		return nil, limited
+	}
+	if errors.Is(err, db.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to list orders", "error", err)
+		return nil, status.Error(codes.Internal, "failed to list orders")
+	}
+	return &ordersv1.ListOrdersResponse{
+		Orders:        ordersToProto(redact.Orders(page.Orders, auth.RoleFromContext(ctx))),
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+func (s *Server) WatchOrders(req *ordersv1.WatchOrdersRequest, stream grpc.ServerStreamingServer[ordersv1.OrderEvent]) error {
+	role := auth.RoleFromContext(stream.Context())
+	sub, missed, complete := s.events.Subscribe(req.GetLastEventId(), watchFilter(req))
+	defer sub.Close()
+
+	if !complete {
+		if err := stream.Send(&ordersv1.OrderEvent{Id: req.GetLastEventId(), Type: "gap"}); err != nil {
+			return err
+		}
+	}
+	for _, event := range missed {
+		if err := stream.Send(eventToProto(event, role)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber is too slow")
+			}
+			if err := stream.Send(eventToProto(event, role)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func watchFilter(req *ordersv1.WatchOrdersRequest) events.Filter {
+	uids := make(map[string]struct{}, len(req.GetOrderUids()))
+	for _, uid := range req.GetOrderUids() {
+		uids[uid] = struct{}{}
+	}
+	customerID, deliveryService := req.GetCustomerId(), req.GetDeliveryService()
+
+	if len(uids) == 0 && customerID == "" && deliveryService == "" {
+		return nil
+	}
+	return func(event events.Event) bool {
+		if len(uids) > 0 {
+			if _, ok := uids[event.Order.OrderUID]; !ok {
+				return false
+			}
+		}
+		if customerID != "" && event.Order.CustomerID != customerID {
+			return false
+		}
+		if deliveryService != "" && event.Order.DeliveryService != deliveryService {
+			return false
+		}
+		return true
+	}
+}
+
+func eventToProto(event events.Event, role auth.Role) *ordersv1.OrderEvent {
+	return &ordersv1.OrderEvent{
+		Id:    event.ID,
+		Type:  string(event.Type),
+		Order: orderToProto(redact.Order(event.Order, role)),
+		At:    timestampToProto(event.At),
+	}
+}
@@ -0,0 +1,201 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	ordersv1 "l0/api/orders/v1"
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/events"
+	"l0/internal/models"
+	"l0/internal/ratelimit"
+	"l0/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func testOrder(uid string) models.Order {
+	return models.Order{
+		OrderUID:    uid,
+		TrackNumber: "WBIL" + uid,
+		CustomerID:  "cust-1",
+		DateCreated: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Payment:     models.Payment{Amount: 1500},
+		Items:       []models.Item{{ChrtID: 1, Price: 0, Name: "Gift"}},
+	}
+}
+
+func newTestClient(t *testing.T, opts ...grpc.ServerOption) (ordersv1.OrderServiceClient, *cache.MockCache, *db.MockDatabase, *events.Broker) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	mockCache := cache.NewMockCache(ctrl)
+	mockDB := db.NewMockDatabase(ctrl)
+	broker := events.NewBroker(10)
+
+	listener := bufconn.Listen(1 << 20)
+	srv := Register(NewServer(service.NewOrders(mockCache, mockDB), broker), opts...)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return ordersv1.NewOrderServiceClient(conn), mockCache, mockDB, broker
+}
+
+func TestServer_GetOrder(t *testing.T) {
+	client, mockCache, _ := newTestClientNoBroker(t)
+	mockCache.EXPECT().Get("g-1").Return(testOrder("g-1"), true)
+
+	order, err := client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{OrderUid: "g-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "g-1", order.GetOrderUid())
+	assert.Equal(t, int64(1500), order.GetPayment().GetAmount())
+	assert.Equal(t, int64(0), order.GetItems()[0].GetPrice())
+	assert.Equal(t, int64(1704164645), order.GetDateCreated().GetSeconds())
+}
+
+func TestServer_GetOrderErrors(t *testing.T) {
+	client, mockCache, mockDB := newTestClientNoBroker(t)
+
+	_, err := client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockCache.EXPECT().Get("missing").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("missing").Return(false)
+	mockCache.EXPECT().Peek("missing").Return(models.Order{}, false)
+	mockDB.EXPECT().GetOrder(gomock.Any(), "missing").Return(nil, db.ErrOrderNotFound)
+	mockCache.EXPECT().MarkMissing("missing")
+
+	_, err = client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{OrderUid: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	mockCache.EXPECT().Get("o-1").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("o-1").Return(false)
+	mockCache.EXPECT().Peek("o-1").Return(models.Order{}, false)
+	mockDB.EXPECT().GetOrder(gomock.Any(), "o-1").Return(nil, assert.AnError)
+
+	_, err = client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{OrderUid: "o-1"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_BatchGetOrders(t *testing.T) {
+	client, mockCache, mockDB := newTestClientNoBroker(t)
+
+	mockCache.EXPECT().Get("b-1").Return(testOrder("b-1"), true)
+	mockCache.EXPECT().Get("b-2").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("b-2").Return(false)
+	mockCache.EXPECT().Get("b-3").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("b-3").Return(false)
+	mockDB.EXPECT().GetOrders(gomock.Any(), []string{"b-2", "b-3"}).Return([]models.Order{testOrder("b-3")}, nil)
+	mockCache.EXPECT().Set("b-3", gomock.Any())
+	mockCache.EXPECT().MarkMissing("b-2")
+
+	resp, err := client.BatchGetOrders(context.Background(), &ordersv1.BatchGetOrdersRequest{OrderUids: []string{"b-1", "b-2", "b-3"}})
+	require.NoError(t, err)
+	require.Len(t, resp.GetOrders(), 2)
+	assert.Equal(t, "b-1", resp.GetOrders()[0].GetOrderUid())
+	assert.Equal(t, "b-3", resp.GetOrders()[1].GetOrderUid())
+	assert.Equal(t, []string{"b-2"}, resp.GetNotFound())
+
+	mockCache.EXPECT().Get("b-4").Return(models.Order{}, false)
+	mockCache.EXPECT().Missing("b-4").Return(false)
+	mockDB.EXPECT().GetOrders(gomock.Any(), []string{"b-4"}).Return(nil, assert.AnError)
+	_, err = client.BatchGetOrders(context.Background(), &ordersv1.BatchGetOrdersRequest{OrderUids: []string{"b-4"}})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_ListOrders(t *testing.T) {
+	client, _, mockDB := newTestClientNoBroker(t)
+
+	mockDB.EXPECT().
+		ListOrders(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, params db.ListOrdersParams) (*db.OrderPage, error) {
+			assert.Equal(t, db.SortByAmount, params.Sort)
+			assert.False(t, params.Desc)
+			assert.Equal(t, "Nike", params.Filter.ItemBrand)
+			assert.Equal(t, 5, params.Limit)
+			return &db.OrderPage{Orders: []models.Order{testOrder("l-1")}, NextCursor: "token"}, nil
+		})
+
+	resp, err := client.ListOrders(context.Background(), &ordersv1.ListOrdersRequest{
+		ItemBrand: "Nike",
+		Sort:      ordersv1.SortField_SORT_FIELD_AMOUNT,
+		Ascending: true,
+		PageSize:  5,
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetOrders(), 1)
+	assert.Equal(t, "token", resp.GetNextPageToken())
+
+	mockDB.EXPECT().ListOrders(gomock.Any(), gomock.Any()).Return(nil, db.ErrInvalidCursor)
+	_, err = client.ListOrders(context.Background(), &ordersv1.ListOrdersRequest{PageToken: "broken"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_WatchOrders(t *testing.T) {
+	client, _, _, broker := newTestClient(t)
+	broker.Publish(events.OrderCreated, testOrder("w-0"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchOrders(ctx, &ordersv1.WatchOrdersRequest{
+		OrderUids:   []string{"w-0", "w-2"},
+		LastEventId: 0,
+	})
+	require.NoError(t, err)
+
+	// Дождаться подписки на сервере, иначе событие может прийти раньше неё.
+	require.Eventually(t, func() bool { return broker.Subscribers() == 1 }, time.Second, time.Millisecond)
+	broker.Publish(events.OrderCreated, testOrder("w-1"))
+	published := broker.Publish(events.OrderStatusChanged, testOrder("w-2"))
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "order.status_changed", event.GetType())
+	assert.Equal(t, "w-2", event.GetOrder().GetOrderUid())
+	assert.Equal(t, published.ID, event.GetId())
+}
+
+func newTestClientNoBroker(t *testing.T) (ordersv1.OrderServiceClient, *cache.MockCache, *db.MockDatabase) {
+	client, mockCache, mockDB, _ := newTestClient(t)
+	return client, mockCache, mockDB
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{DB: ratelimit.Limit{Rate: 1, Burst: 1}})
+	client, mockCache, mockDB, _ := newTestClient(t, RateLimitInterceptors(limiter)...)
+
+	order := testOrder("rl-1")
+	mockCache.EXPECT().Get(gomock.Any()).Return(models.Order{}, false).Times(2)
+	mockCache.EXPECT().Missing(gomock.Any()).Return(false).AnyTimes()
+	mockCache.EXPECT().Peek(gomock.Any()).Return(models.Order{}, false).AnyTimes()
+	mockDB.EXPECT().GetOrder(gomock.Any(), "rl-1").Return(&order, nil)
+	mockCache.EXPECT().Set("rl-1", gomock.Any())
+
+	_, err := client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{OrderUid: "rl-1"})
+	require.NoError(t, err)
+
+	var trailer metadata.MD
+	_, err = client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{OrderUid: "rl-2"}, grpc.Trailer(&trailer))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"1"}, trailer.Get("retry-after"))
+}
@@ -0,0 +1,173 @@
+// Package health собирает готовность сервиса из проверок зависимостей
+// (PostgreSQL, Kafka, прогрев кэша) для эндпоинтов /healthz и /readyz.
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCheckTimeout ограничивает одну проверку, чтобы зависшая зависимость
+// не задерживала ответ /readyz дольше таймаута пробы оркестратора.
+const DefaultCheckTimeout = 2 * time.Second
+
+// Check проверяет зависимость. details попадают в ответ /readyz как есть
+// и могут быть заполнены, даже если проверка не прошла.
+type Check func(ctx context.Context) (details map[string]interface{}, err error)
+
+type Status string
+
+const (
+	StatusReady    Status = "ready"
+	StatusNotReady Status = "not_ready"
+	StatusDraining Status = "draining"
+
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+type CheckResult struct {
+	Status    Status                 `json:"status"`
+	Error     string                 `json:"error,omitempty"`
+	LatencyMS float64                `json:"latency_ms"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func (r Report) Ready() bool {
+	return r.Status == StatusReady
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Readiness хранит проверки зависимостей и признак остановки сервиса.
+type Readiness struct {
+	mu       sync.RWMutex
+	checks   []namedCheck
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{timeout: DefaultCheckTimeout}
+}
+
+// Register добавляет проверку. Повторная регистрация имени заменяет проверку.
+func (r *Readiness) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.checks {
+		if r.checks[i].name == name {
+			r.checks[i].check = check
+			return
+		}
+	}
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+	sort.Slice(r.checks, func(i, j int) bool { return r.checks[i].name < r.checks[j].name })
+}
+
+// Drain переводит сервис в состояние остановки: /readyz отвечает 503, чтобы балансировщик
+// перестал присылать новые запросы, пока текущие дорабатывают.
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+func (r *Readiness) Draining() bool {
+	return r.draining.Load()
+}
+
+// Check выполняет все проверки параллельно. Сервис готов, если прошли все проверки
+// и он не останавливается.
+func (r *Readiness) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]namedCheck(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, c.check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusReady, Checks: make(map[string]CheckResult, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusNotReady
+		}
+	}
+	if r.Draining() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+func (r *Readiness) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Flag - готовность, которую выставляет сам сервис, например по окончании прогрева кэша.
+type Flag struct {
+	mu      sync.RWMutex
+	ready   bool
+	reason  string
+	details map[string]interface{}
+}
+
+// NewFlag создаёт неготовый флаг; reason возвращается проверкой, пока флаг не выставлен.
+func NewFlag(reason string) *Flag {
+	return &Flag{reason: reason}
+}
+
+// Set меняет состояние флага и подробности, которые показывает /readyz.
+func (f *Flag) Set(ready bool, details map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ready = ready
+	f.details = details
+}
+
+func (f *Flag) Check(context.Context) (map[string]interface{}, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	details := make(map[string]interface{}, len(f.details))
+	for k, v := range f.details {
+		details[k] = v
+	}
+	if !f.ready {
+		return details, errors.New(f.reason)
+	}
+	return details, nil
+}
@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func up(context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}{"version": "16"}, nil
+}
+
+func TestReadiness_AllChecksPass(t *testing.T) {
+	r := NewReadiness()
+	r.Register("postgres", up)
+	r.Register("kafka", up)
+
+	report := r.Check(context.Background())
+	assert.True(t, report.Ready())
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusUp, report.Checks["postgres"].Status)
+	assert.Equal(t, "16", report.Checks["postgres"].Details["version"])
+}
+
+func TestReadiness_FailedCheck(t *testing.T) {
+	r := NewReadiness()
+	r.Register("postgres", up)
+	r.Register("kafka", func(context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"lag": 5}, errors.New("no broker")
+	})
+
+	report := r.Check(context.Background())
+	assert.Equal(t, StatusNotReady, report.Status)
+	assert.Equal(t, CheckResult{Status: StatusDown, Error: "no broker", Details: map[string]interface{}{"lag": 5}},
+		withoutLatency(report.Checks["kafka"]))
+	assert.Equal(t, StatusUp, report.Checks["postgres"].Status)
+}
+
+func TestReadiness_CheckTimeout(t *testing.T) {
+	r := NewReadiness()
+	r.timeout = 20 * time.Millisecond
+	r.Register("slow", func(ctx context.Context) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	report := r.Check(context.Background())
+	assert.Equal(t, StatusNotReady, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestReadiness_Drain(t *testing.T) {
+	r := NewReadiness()
+	r.Register("postgres", up)
+	require.True(t, r.Check(context.Background()).Ready())
+
+	r.Drain()
+	report := r.Check(context.Background())
+	assert.Equal(t, StatusDraining, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["postgres"].Status)
+}
+
+func TestReadiness_RegisterReplaces(t *testing.T) {
+	r := NewReadiness()
+	r.Register("cache", func(context.Context) (map[string]interface{}, error) { return nil, errors.New("cold") })
+	r.Register("cache", up)
+
+	report := r.Check(context.Background())
+	assert.True(t, report.Ready())
+	assert.Len(t, report.Checks, 1)
+}
+
+func TestFlag(t *testing.T) {
+	flag := NewFlag("warming up")
+
+	details, err := flag.Check(context.Background())
+	assert.EqualError(t, err, "warming up")
+	assert.Empty(t, details)
+
+	flag.Set(false, map[string]interface{}{"loaded": 10})
+	details, err = flag.Check(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 10, details["loaded"])
+
+	flag.Set(true, map[string]interface{}{"loaded": 30})
+	details, err = flag.Check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 30, details["loaded"])
+}
+
+func withoutLatency(r CheckResult) CheckResult {
+	r.LatencyMS = 0
+	return r
+}
@@ -3,10 +3,14 @@ package kafka
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
+	"time"
 
 	"l0/internal/cache"
 	"l0/internal/db"
+	"l0/internal/domain"
+	"l0/internal/events"
+	"l0/internal/logging"
 	"l0/internal/models"
 	"l0/internal/utils"
 
@@ -14,24 +18,41 @@ import (
 )
 
 type Consumer interface {
-	StartConsumer(ctx context.Context, brokers []string, topic string, db db.Database, cacheService cache.Cache) error
+	StartConsumer(ctx context.Context, brokers []string, topic string, db db.Database, cacheService cache.Cache, publisher events.Publisher) error
 }
 
-type KafkaConsumer struct{}
+// consumerGroupID - группа консьюмеров заказов; по её закоммиченным offset'ам Monitor считает отставание.
+const consumerGroupID = "order-processor"
 
-func (k *KafkaConsumer) StartConsumer(ctx context.Context, brokers []string, topic string, dbService db.Database, cacheService cache.Cache) error {
+type KafkaConsumer struct {
+	// Monitor, если задан, получает состояние консьюмера для проверки готовности.
+	Monitor *Monitor
+}
+
+func (k *KafkaConsumer) StartConsumer(ctx context.Context, brokers []string, topic string, dbService db.Database, cacheService cache.Cache, publisher events.Publisher) error {
 	r := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: brokers,
 		Topic:   topic,
-		GroupID: "order-processor",
+		GroupID: consumerGroupID,
 	})
 
+	logger := logging.FromContext(ctx).With("topic", topic)
 	defer func() {
 		if err := r.Close(); err != nil {
-			log.Printf("Failed to close Kafka reader: %v", err)
+			logger.Error("Failed to close Kafka reader", "error", err)
+		}
+	}()
+
+	dlq := newDeadLetterQueue(brokers, topic)
+	defer func() {
+		if err := dlq.Close(); err != nil {
+			logger.Error("Failed to close DLQ writer", "error", err)
 		}
 	}()
 
+	k.Monitor.setRunning(true)
+	defer k.Monitor.setRunning(false)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -41,33 +62,69 @@ func (k *KafkaConsumer) StartConsumer(ctx context.Context, brokers []string, top
 			if err != nil {
 				return err
 			}
+			k.Monitor.observe(time.Now())
+			handleMessage(ctx, logger, msg, dlq, dbService, cacheService, publisher)
+		}
+	}
+}
 
-			var order models.Order
-			if err := json.Unmarshal(msg.Value, &order); err != nil {
-				log.Printf("Failed to unmarshal order: %v", err)
-				continue
-			}
+// handleMessage обрабатывает одно сообщение. Все строки лога, в том числе из слоя БД,
+// содержат ключ, партицию и offset сообщения.
+func handleMessage(
+	ctx context.Context,
+	logger *slog.Logger,
+	msg kafka.Message,
+	dlq *deadLetterQueue,
+	dbService db.Database,
+	cacheService cache.Cache,
+	publisher events.Publisher,
+) {
+	logger = logger.With("key", string(msg.Key), "partition", msg.Partition, "offset", msg.Offset)
+	ctx = logging.WithLogger(ctx, logger)
 
-			if err := utils.ValidateStruct(order); err != nil {
-				log.Printf("Invalid order data: %v", err)
-				continue
-			}
+	var order models.Order
+	if err := json.Unmarshal(msg.Value, &order); err != nil {
+		logger.Warn("Failed to unmarshal order", "error", err)
+		if err := dlq.Send(ctx, msg, dlqReasonInvalidJSON, err, nil); err != nil {
+			logger.Error("Failed to send message to DLQ", "error", err)
+		}
+		return
+	}
+	logger = logger.With("order_uid", order.OrderUID)
+	ctx = logging.WithLogger(ctx, logger)
 
-			// Сохраняем в БД
-			if err := dbService.SaveOrder(ctx, order); err != nil {
-				log.Printf("Failed to save order to DB: %v", err)
-				continue
-			}
+	if err := utils.ValidateStruct(order); err != nil {
+		validationErrors := utils.GetValidationErrors(err)
+		logger.Warn("Invalid order data", "validation_errors", len(validationErrors))
+		if err := dlq.Send(ctx, msg, dlqReasonValidationFailed, err, validationErrors); err != nil {
+			logger.Error("Failed to send message to DLQ", "error", err)
+		}
+		return
+	}
 
-			// Сохраняем в кэш
-			cacheService.Set(order.OrderUID, order)
+	domain.InitStatus(&order, time.Now().UTC())
 
-			log.Printf("Processed order: %s", order.OrderUID)
-		}
+	// Сохраняем в БД
+	created, err := dbService.SaveOrder(ctx, order)
+	if err != nil {
+		logger.Error("Failed to save order to DB", "error", err)
+		return
+	}
+	if !created {
+		// Повторная доставка: в БД уже лежит заказ с актуальным статусом,
+		// кэш и подписчиков трогать не нужно.
+		logger.Info("Order already exists, skipping")
+		return
 	}
+
+	// Сохраняем в кэш
+	cacheService.Set(order.OrderUID, order)
+	publisher.Publish(events.OrderCreated, order)
+
+	logger.Info("Processed order")
 }
 
-func StartConsumer(ctx context.Context, brokers []string, topic string, db db.Database, cache cache.Cache) error {
+func StartConsumer(ctx context.Context, brokers []string, topic string, db db.Database, cache cache.Cache, publisher events.Publisher) error {
 	consumer := &KafkaConsumer{}
-	return consumer.StartConsumer(ctx, brokers, topic, db, cache)
+	return consumer.StartConsumer(ctx, brokers, topic, db, cache, publisher)
 }
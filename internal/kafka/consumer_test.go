@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/events"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOrderJSON = `{
+	"order_uid": "kafka-1", "track_number": "WBILMTESTTRACK", "entry": "WBIL",
+	"delivery": {"name": "Test Testov", "phone": "+9720000000", "zip": "2639809", "city": "Kiryat Mozkin",
+		"address": "Ploshad Mira 15", "region": "Kraiot", "email": "test@gmail.com"},
+	"payment": {"transaction": "kafka-1", "request_id": "", "currency": "USD", "provider": "wbpay", "amount": 1817,
+		"payment_dt": 1637907727, "bank": "alpha", "delivery_cost": 1500, "goods_total": 317, "custom_fee": 0},
+	"items": [{"chrt_id": 9934930, "track_number": "WBILMTESTTRACK", "price": 453, "rid": "ab4219087a764ae0btest",
+		"name": "Mascaras", "sale": 30, "size": "0", "total_price": 317, "nm_id": 2389212,
+		"brand": "Vivienne Sabo", "status": 202}],
+	"locale": "en", "internal_signature": "", "customer_id": "test", "delivery_service": "meest",
+	"shardkey": "9", "sm_id": 99, "date_created": "2021-11-26T06:22:19Z", "oof_shard": "1"
+}`
+
+type countingPublisher struct {
+	published int
+}
+
+func (p *countingPublisher) Publish(eventType events.Type, order models.Order) events.Event {
+	p.published++
+	return events.Event{Type: eventType, Order: order}
+}
+
+func TestHandleMessage_SkipsRedeliveredOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDatabase(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	publisher := &countingPublisher{}
+	msg := kafka.Message{Key: []byte("kafka-1"), Value: []byte(testOrderJSON)}
+
+	mockDB.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(true, nil)
+	mockCache.EXPECT().Set("kafka-1", gomock.Any())
+	handleMessage(context.Background(), slog.Default(), msg, nil, mockDB, mockCache, publisher)
+
+	// Повторная доставка не трогает кэш и не публикует второе событие.
+	mockDB.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(false, nil)
+	handleMessage(context.Background(), slog.Default(), msg, nil, mockDB, mockCache, publisher)
+
+	assert.Equal(t, 1, publisher.published)
+}
@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"l0/internal/utils"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	dlqTopicSuffix = "-dlq"
+
+	dlqReasonInvalidJSON      = "invalid_json"
+	dlqReasonValidationFailed = "validation_failed"
+)
+
+// deadLetterQueue публикует сообщения, которые не удалось обработать, в отдельный топик.
+// В заголовках передаются причина, исходные координаты сообщения и ошибки валидации.
+type deadLetterQueue struct {
+	writer *kafka.Writer
+}
+
+func newDeadLetterQueue(brokers []string, topic string) *deadLetterQueue {
+	return &deadLetterQueue{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic + dlqTopicSuffix,
+			Balancer:               &kafka.LeastBytes{},
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (q *deadLetterQueue) Send(ctx context.Context, msg kafka.Message, reason string, cause error, validationErrors []utils.ValidationError) error {
+	headers := []kafka.Header{
+		{Key: "dlq-reason", Value: []byte(reason)},
+		{Key: "dlq-error", Value: []byte(cause.Error())},
+		{Key: "dlq-source-topic", Value: []byte(msg.Topic)},
+		{Key: "dlq-source-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+		{Key: "dlq-source-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		{Key: "dlq-failed-at", Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	}
+
+	if len(validationErrors) > 0 {
+		encoded, err := json.Marshal(validationErrors)
+		if err != nil {
+			return err
+		}
+		headers = append(headers, kafka.Header{Key: "dlq-validation-errors", Value: encoded})
+	}
+
+	return q.writer.WriteMessages(ctx, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+}
+
+func (q *deadLetterQueue) Close() error {
+	return q.writer.Close()
+}
@@ -0,0 +1,167 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// lagCheckInterval - сколько переиспользуется отставание, полученное от брокеров,
+// чтобы частые пробы /readyz не превращались в запросы к Kafka.
+const lagCheckInterval = 5 * time.Second
+
+// Monitor следит за состоянием консьюмера для проверки готовности:
+// доступность брокеров и отставание группы от конца партиций.
+type Monitor struct {
+	// maxLag - допустимое суммарное отставание в сообщениях; 0 - не проверять.
+	maxLag int64
+	// fetchLag запрашивает у брокеров отставание группы по партициям.
+	fetchLag func(ctx context.Context) (map[int]int64, error)
+	now      func() time.Time
+
+	mu          sync.Mutex
+	running     bool
+	lastMessage time.Time
+
+	// lagMu защищает последний ответ брокеров отдельно от mu, чтобы запрос
+	// к Kafka не задерживал observe на пути обработки сообщений.
+	lagMu     sync.Mutex
+	checkedAt time.Time
+	lag       map[int]int64
+	lagErr    error
+}
+
+func NewMonitor(brokers []string, topic string, maxLag int64) *Monitor {
+	// Клиент держит соединения с брокерами в пуле транспорта и переиспользует их между проверками.
+	client := &kafka.Client{Addr: kafka.TCP(brokers...), Timeout: lagCheckInterval}
+	return &Monitor{
+		maxLag: maxLag,
+		fetchLag: func(ctx context.Context) (map[int]int64, error) {
+			return groupLag(ctx, client, topic, consumerGroupID)
+		},
+		now: time.Now,
+	}
+}
+
+// groupLag считает отставание группы как разницу между концом партиции и закоммиченным
+// offset'ом. Зависший консьюмер не коммитит, поэтому его отставание растёт с новыми сообщениями.
+func groupLag(ctx context.Context, client *kafka.Client, topic, groupID string) (map[int]int64, error) {
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, fmt.Errorf("no Kafka broker is reachable: %v", err)
+	}
+	if len(metadata.Topics) != 1 {
+		return nil, fmt.Errorf("topic %q not found", topic)
+	}
+	if err := metadata.Topics[0].Error; err != nil {
+		return nil, fmt.Errorf("failed to get metadata of topic %q: %v", topic, err)
+	}
+
+	topicPartitions := metadata.Topics[0].Partitions
+	partitions := make([]int, 0, len(topicPartitions))
+	requests := make([]kafka.OffsetRequest, 0, 2*len(topicPartitions))
+	for _, p := range topicPartitions {
+		partitions = append(partitions, p.ID)
+		requests = append(requests, kafka.FirstOffsetOf(p.ID), kafka.LastOffsetOf(p.ID))
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{
+		GroupID: groupID,
+		Topics:  map[string][]int{topic: partitions},
+	})
+	if err == nil {
+		err = committed.Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets: %v", err)
+	}
+	ends, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{topic: requests}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partition offsets: %v", err)
+	}
+
+	offsets := make(map[int]int64, len(partitions))
+	for _, p := range committed.Topics[topic] {
+		if p.Error != nil {
+			return nil, fmt.Errorf("failed to fetch committed offset of partition %d: %v", p.Partition, p.Error)
+		}
+		offsets[p.Partition] = p.CommittedOffset
+	}
+
+	lag := make(map[int]int64, len(partitions))
+	for _, p := range ends.Topics[topic] {
+		if p.Error != nil {
+			return nil, fmt.Errorf("failed to list offsets of partition %d: %v", p.Partition, p.Error)
+		}
+		// Без закоммиченного offset'а группа читает партицию с начала.
+		from, ok := offsets[p.Partition]
+		if !ok || from < 0 {
+			from = p.FirstOffset
+		}
+		lag[p.Partition] = max(p.LastOffset-from, 0)
+	}
+	return lag, nil
+}
+
+func (m *Monitor) setRunning(running bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running = running
+}
+
+// observe запоминает время последнего прочитанного сообщения.
+func (m *Monitor) observe(at time.Time) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastMessage = at
+}
+
+// Check проверяет, что консьюмер запущен, брокеры отвечают и отставание
+// не превышает maxLag.
+func (m *Monitor) Check(ctx context.Context) (map[string]interface{}, error) {
+	m.mu.Lock()
+	running, lastMessage := m.running, m.lastMessage
+	m.mu.Unlock()
+
+	partitions, lagErr := m.currentLag(ctx)
+	var lag int64
+	for _, l := range partitions {
+		lag += l
+	}
+	details := map[string]interface{}{"lag": lag, "partitions": len(partitions)}
+	if !lastMessage.IsZero() {
+		details["last_message_at"] = lastMessage.UTC().Format(time.RFC3339)
+	}
+
+	switch {
+	case !running:
+		return details, errors.New("consumer is not running")
+	case lagErr != nil:
+		return details, lagErr
+	case m.maxLag > 0 && lag > m.maxLag:
+		return details, fmt.Errorf("consumer lag %d exceeds %d", lag, m.maxLag)
+	}
+	return details, nil
+}
+
+// currentLag возвращает отставание по партициям, запрашивая его у брокеров
+// не чаще раза в lagCheckInterval.
+func (m *Monitor) currentLag(ctx context.Context) (map[int]int64, error) {
+	m.lagMu.Lock()
+	defer m.lagMu.Unlock()
+	if now := m.now(); m.checkedAt.IsZero() || now.Sub(m.checkedAt) >= lagCheckInterval {
+		m.lag, m.lagErr = m.fetchLag(ctx)
+		m.checkedAt = now
+	}
+	return m.lag, m.lagErr
+}
@@ -0,0 +1,46 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitor_Check(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	lag := map[int]int64{0: 2, 1: 0}
+	var fetchErr error
+	fetches := 0
+	m := NewMonitor([]string{"b1:9092"}, "orders", 10)
+	m.now = func() time.Time { return now }
+	m.fetchLag = func(context.Context) (map[int]int64, error) {
+		fetches++
+		return lag, fetchErr
+	}
+
+	_, err := m.Check(context.Background())
+	assert.EqualError(t, err, "consumer is not running")
+
+	m.setRunning(true)
+	m.observe(now)
+	details, err := m.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"lag": int64(2), "partitions": 2, "last_message_at": "2024-01-02T03:04:05Z"}, details)
+	assert.Equal(t, 1, fetches, "lag is reused between frequent probes")
+
+	// Консьюмер завис: сообщения не читаются, а отставание группы растёт.
+	lag = map[int]int64{0: 2, 1: 15}
+	now = now.Add(lagCheckInterval)
+	_, err = m.Check(context.Background())
+	assert.EqualError(t, err, "consumer lag 17 exceeds 10")
+	assert.Equal(t, 2, fetches)
+
+	fetchErr = errors.New("no Kafka broker is reachable: connection refused")
+	now = now.Add(lagCheckInterval)
+	_, err = m.Check(context.Background())
+	assert.EqualError(t, err, "no Kafka broker is reachable: connection refused")
+}
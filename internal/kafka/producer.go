@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"l0/internal/models"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Producer публикует заказы в топик, который читает консьюмер.
+type Producer struct {
+	writer *kafka.Writer
+}
+
+func NewProducer(brokers []string, topic string) *Producer {
+	return &Producer{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// PublishOrders отправляет заказы одной пачкой, ключ сообщения - order_uid.
+func (p *Producer) PublishOrders(ctx context.Context, orders ...models.Order) error {
+	messages := make([]kafka.Message, 0, len(orders))
+	for _, order := range orders {
+		value, err := json.Marshal(order)
+		if err != nil {
+			return fmt.Errorf("failed to marshal order %s: %v", order.OrderUID, err)
+		}
+		messages = append(messages, kafka.Message{
+			Key:   []byte(order.OrderUID),
+			Value: value,
+		})
+	}
+
+	if err := p.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to publish orders: %v", err)
+	}
+	return nil
+}
+
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
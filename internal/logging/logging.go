@@ -0,0 +1,112 @@
+// Package logging настраивает структурированные логи (log/slog) и переносит
+// логгер с атрибутами запроса или сообщения Kafka через context.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New создаёт логгер с выводом в w в формате json или text и минимальным уровнем level
+// (debug, info, warn, error).
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+}
+
+type loggerKey struct{}
+
+// WithLogger сохраняет в контексте логгер с атрибутами текущей операции.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext возвращает логгер операции или slog.Default, если его нет.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+type requestIDKey struct{}
+
+// NewRequestID генерирует идентификатор запроса из 16 случайных байт.
+func NewRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// CacheStats считает обращения к кэшу в рамках одного запроса, чтобы лог запроса
+// показывал, был ли он обслужен из кэша.
+type CacheStats struct {
+	hits, misses atomic.Int64
+}
+
+type cacheStatsKey struct{}
+
+func WithCacheStats(ctx context.Context, stats *CacheStats) context.Context {
+	return context.WithValue(ctx, cacheStatsKey{}, stats)
+}
+
+// RecordCacheLookup отмечает попадание или промах кэша для текущего запроса.
+func RecordCacheLookup(ctx context.Context, hit bool) {
+	stats, ok := ctx.Value(cacheStatsKey{}).(*CacheStats)
+	if !ok {
+		return
+	}
+	if hit {
+		stats.hits.Add(1)
+	} else {
+		stats.misses.Add(1)
+	}
+}
+
+// Attr описывает обращения к кэшу: hit, miss или partial (часть заказов из БД).
+// Если кэш не использовался, возвращает пустой атрибут, который slog не выводит.
+func (s *CacheStats) Attr() slog.Attr {
+	hits, misses := s.hits.Load(), s.misses.Load()
+	switch {
+	case hits == 0 && misses == 0:
+		return slog.Attr{}
+	case misses == 0:
+		return slog.String("cache", "hit")
+	case hits == 0:
+		return slog.String("cache", "miss")
+	default:
+		return slog.String("cache", "partial")
+	}
+}
@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "json", "warn")
+	require.NoError(t, err)
+
+	logger.Info("hidden")
+	logger.Warn("shown", "key", "value")
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), `"msg":"shown","key":"value"`)
+
+	buf.Reset()
+	logger, err = New(&buf, "text", "debug")
+	require.NoError(t, err)
+	logger.Debug("details")
+	assert.Contains(t, buf.String(), "msg=details")
+
+	_, err = New(&buf, "xml", "info")
+	assert.Error(t, err)
+	_, err = New(&buf, "json", "verbose")
+	assert.Error(t, err)
+}
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "json", "info")
+	require.NoError(t, err)
+
+	ctx := WithLogger(context.Background(), logger.With("request_id", "r-1"))
+	FromContext(ctx).Info("message")
+	assert.Contains(t, buf.String(), `"request_id":"r-1"`)
+}
+
+func TestCacheStats(t *testing.T) {
+	stats := &CacheStats{}
+	assert.Empty(t, stats.Attr().Key)
+
+	ctx := WithCacheStats(context.Background(), stats)
+	RecordCacheLookup(ctx, true)
+	assert.Equal(t, "hit", stats.Attr().Value.String())
+	RecordCacheLookup(ctx, false)
+	assert.Equal(t, "partial", stats.Attr().Value.String())
+
+	missOnly := &CacheStats{}
+	RecordCacheLookup(WithCacheStats(context.Background(), missOnly), false)
+	assert.Equal(t, "miss", missOnly.Attr().Value.String())
+
+	// Без счётчика в контексте вызов ничего не делает.
+	RecordCacheLookup(context.Background(), true)
+}
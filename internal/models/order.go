@@ -8,25 +8,29 @@ type Order struct {
 	Entry             string    `json:"entry" db:"entry" validate:"required"`
 	Delivery          Delivery  `json:"delivery" validate:"required"`
 	Payment           Payment   `json:"payment" validate:"required"`
-	Items             []Item    `json:"items" validate:"required"`
+	Items             []Item    `json:"items" validate:"required,min=1,dive"`
 	Locale            string    `json:"locale" db:"locale" validate:"required"`
 	InternalSignature string    `json:"internal_signature" db:"internal_signature"`
 	CustomerID        string    `json:"customer_id" db:"customer_id" validate:"required"`
 	DeliveryService   string    `json:"delivery_service" db:"delivery_service" validate:"required"`
 	ShardKey          string    `json:"shardkey" db:"shardkey" validate:"required"`
-	SMID              int       `json:"sm_id" db:"sm_id" validate:"required"`
+	SMID              int       `json:"sm_id" db:"sm_id" validate:"present,min=0"`
 	DateCreated       time.Time `json:"date_created" db:"date_created" validate:"required"`
 	OOFShard          string    `json:"oof_shard" db:"oof_shard" validate:"required"`
+	Status            string    `json:"status,omitempty" db:"status" validate:"omitempty,oneof=created paid assembled shipped delivered cancelled returned"`
+	UpdatedAt         time.Time `json:"updated_at,omitzero" db:"updated_at"`
+
+	present presence
 }
 
 type Delivery struct {
-	Name    string `json:"name" db:"name" validate:"required"`
-	Phone   string `json:"phone" db:"phone" validate:"required"`
-	Zip     string `json:"zip" db:"zip" validate:"required"`
+	Name    string `json:"name,omitempty" db:"name" validate:"required" pii:"true"`
+	Phone   string `json:"phone,omitempty" db:"phone" validate:"required" pii:"true"`
+	Zip     string `json:"zip,omitempty" db:"zip" validate:"required" pii:"true"`
 	City    string `json:"city" db:"city" validate:"required"`
-	Address string `json:"address" db:"address" validate:"required"`
+	Address string `json:"address,omitempty" db:"address" validate:"required" pii:"true"`
 	Region  string `json:"region" db:"region" validate:"required"`
-	Email   string `json:"email" db:"email" validate:"required,email"`
+	Email   string `json:"email,omitempty" db:"email" validate:"required,email" pii:"true"`
 }
 
 type Payment struct {
@@ -34,24 +38,28 @@ type Payment struct {
 	RequestID    string `json:"request_id" db:"request_id"`
 	Currency     string `json:"currency" db:"currency" validate:"required"`
 	Provider     string `json:"provider" db:"provider" validate:"required"`
-	Amount       int    `json:"amount" db:"amount" validate:"required"`
-	PaymentDT    int64  `json:"payment_dt" db:"payment_dt" validate:"required"`
+	Amount       int    `json:"amount" db:"amount" validate:"present,min=0"`
+	PaymentDT    int64  `json:"payment_dt" db:"payment_dt" validate:"required,min=1"`
 	Bank         string `json:"bank" db:"bank" validate:"required"`
-	DeliveryCost int    `json:"delivery_cost" db:"delivery_cost"`
-	GoodsTotal   int    `json:"goods_total" db:"goods_total"`
-	CustomFee    int    `json:"custom_fee" db:"custom_fee"`
+	DeliveryCost int    `json:"delivery_cost" db:"delivery_cost" validate:"min=0"`
+	GoodsTotal   int    `json:"goods_total" db:"goods_total" validate:"min=0"`
+	CustomFee    int    `json:"custom_fee" db:"custom_fee" validate:"min=0"`
+
+	present presence
 }
 
 type Item struct {
-	ChrtID      int    `json:"chrt_id" db:"chrt_id" validate:"required"`
+	ChrtID      int    `json:"chrt_id" db:"chrt_id" validate:"required,min=1"`
 	TrackNumber string `json:"track_number" db:"track_number" validate:"required"`
-	Price       int    `json:"price" db:"price" validate:"required"`
+	Price       int    `json:"price" db:"price" validate:"present,min=0"`
 	RID         string `json:"rid" db:"rid" validate:"required"`
 	Name        string `json:"name" db:"name" validate:"required"`
-	Sale        int    `json:"sale" db:"sale"`
+	Sale        int    `json:"sale" db:"sale" validate:"min=0,max=100"`
 	Size        string `json:"size" db:"size" validate:"required"`
-	TotalPrice  int    `json:"total_price" db:"total_price" validate:"required"`
-	NMID        int    `json:"nm_id" db:"nm_id" validate:"required"`
+	TotalPrice  int    `json:"total_price" db:"total_price" validate:"present,min=0"`
+	NMID        int    `json:"nm_id" db:"nm_id" validate:"required,min=1"`
 	Brand       string `json:"brand" db:"brand" validate:"required"`
-	Status      int    `json:"status" db:"status" validate:"required"`
+	Status      int    `json:"status" db:"status" validate:"present,min=0"`
+
+	present presence
 }
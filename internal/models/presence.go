@@ -0,0 +1,84 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// presence хранит JSON-ключи, которые реально пришли во входном сообщении.
+// Нужна, чтобы отличать отсутствующее числовое поле от явно переданного нуля.
+type presence map[string]struct{}
+
+// PresenceTracker реализуют модели, которые помнят, какие поля были переданы в JSON.
+type PresenceTracker interface {
+	Has(jsonField string) bool
+}
+
+func (p presence) has(jsonField string) bool {
+	// Структура собрана в коде, а не декодирована из JSON - считаем поля переданными.
+	if p == nil {
+		return true
+	}
+	_, ok := p[strings.ToLower(jsonField)]
+	return ok
+}
+
+func decodePresence(data []byte) (presence, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	present := make(presence, len(raw))
+	for key, value := range raw {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			continue
+		}
+		present[strings.ToLower(key)] = struct{}{}
+	}
+	return present, nil
+}
+
+func (o Order) Has(jsonField string) bool   { return o.present.has(jsonField) }
+func (p Payment) Has(jsonField string) bool { return p.present.has(jsonField) }
+func (i Item) Has(jsonField string) bool    { return i.present.has(jsonField) }
+
+func (o *Order) UnmarshalJSON(data []byte) error {
+	type plain Order
+	if err := json.Unmarshal(data, (*plain)(o)); err != nil {
+		return err
+	}
+	present, err := decodePresence(data)
+	if err != nil {
+		return err
+	}
+	o.present = present
+	return nil
+}
+
+func (p *Payment) UnmarshalJSON(data []byte) error {
+	type plain Payment
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	present, err := decodePresence(data)
+	if err != nil {
+		return err
+	}
+	p.present = present
+	return nil
+}
+
+func (i *Item) UnmarshalJSON(data []byte) error {
+	type plain Item
+	if err := json.Unmarshal(data, (*plain)(i)); err != nil {
+		return err
+	}
+	present, err := decodePresence(data)
+	if err != nil {
+		return err
+	}
+	i.present = present
+	return nil
+}
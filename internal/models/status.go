@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// StatusChange - запись истории смены статуса заказа.
+type StatusChange struct {
+	From      string    `json:"from,omitempty" db:"from_status"`
+	To        string    `json:"to" db:"to_status"`
+	Reason    string    `json:"reason,omitempty" db:"reason"`
+	ChangedAt time.Time `json:"changed_at" db:"changed_at"`
+}
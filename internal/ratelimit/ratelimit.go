@@ -0,0 +1,277 @@
+// Package ratelimit ограничивает частоту запросов клиентов token bucket'ами.
+// У каждого клиента два независимых бюджета: дешёвые ответы из кэша и
+// обращения к PostgreSQL при промахе кэша.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Budget string
+
+const (
+	// BudgetCache расходуется на ответы, полностью собранные из кэша.
+	BudgetCache Budget = "cache"
+	// BudgetDB расходуется на каждый запрос к БД: промах кэша, список, выгрузку.
+	BudgetDB Budget = "db"
+)
+
+var budgets = []Budget{BudgetCache, BudgetDB}
+
+// DefaultIdleTTL - через сколько без запросов состояние клиента забывается.
+const DefaultIdleTTL = 10 * time.Minute
+
+// Limit - скорость пополнения бюджета (токенов в секунду) и его ёмкость.
+// Rate <= 0 отключает ограничение.
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+func (l Limit) unlimited() bool {
+	return l.Rate <= 0
+}
+
+// normalizeLimit гарантирует, что в бюджет помещается хотя бы один запрос.
+func normalizeLimit(l Limit) Limit {
+	if !l.unlimited() && l.Burst < 1 {
+		l.Burst = int(math.Max(1, math.Ceil(l.Rate)))
+	}
+	return l
+}
+
+type Config struct {
+	Cache   Limit
+	DB      Limit
+	IdleTTL time.Duration
+}
+
+// LimitError возвращается, когда бюджет клиента исчерпан.
+type LimitError struct {
+	Budget     Budget
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s budget, retry after %s", e.Budget, e.RetryAfter)
+}
+
+// RetryAfterSeconds округляет RetryAfter вверх до целых секунд, но не меньше одной:
+// столько HTTP и gRPC API предлагают клиенту подождать.
+func (e *LimitError) RetryAfterSeconds() int {
+	return max(1, int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	allowed  uint64
+	rejected uint64
+}
+
+type client struct {
+	buckets  map[Budget]*bucket
+	lastSeen time.Time
+}
+
+// numShards - число шардов клиентов: запросы разных клиентов не ждут одну блокировку.
+const numShards = 32
+
+type shard struct {
+	mu      sync.Mutex
+	clients map[string]*client
+}
+
+// Limiter хранит бюджеты клиентов в памяти процесса.
+type Limiter struct {
+	limits  map[Budget]Limit
+	idleTTL time.Duration
+	shards  []shard
+	// lastSweep - время последней очистки в UnixNano.
+	lastSweep atomic.Int64
+	now       func() time.Time
+}
+
+func New(cfg Config) *Limiter {
+	if cfg.IdleTTL <= 0 {
+		cfg.IdleTTL = DefaultIdleTTL
+	}
+	cfg.Cache, cfg.DB = normalizeLimit(cfg.Cache), normalizeLimit(cfg.DB)
+	shards := make([]shard, numShards)
+	for i := range shards {
+		shards[i].clients = make(map[string]*client)
+	}
+	return &Limiter{
+		limits:  map[Budget]Limit{BudgetCache: cfg.Cache, BudgetDB: cfg.DB},
+		idleTTL: cfg.IdleTTL,
+		shards:  shards,
+		now:     time.Now,
+	}
+}
+
+// getShard выбирает шард по FNV-1a хешу ключа клиента.
+func (l *Limiter) getShard(key string) *shard {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return &l.shards[h%numShards]
+}
+
+// Allow списывает токен из бюджета клиента. Если токенов нет, возвращает *LimitError
+// со временем, через которое появится следующий токен.
+func (l *Limiter) Allow(key string, budget Budget) error {
+	limit := l.limits[budget]
+	if limit.unlimited() {
+		return nil
+	}
+	now := l.now()
+	l.sweep(now)
+
+	s := l.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.clients[key]
+	if !ok {
+		c = &client{buckets: make(map[Budget]*bucket, len(budgets))}
+		s.clients[key] = c
+	}
+	c.lastSeen = now
+
+	b, ok := c.buckets[budget]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		c.buckets[budget] = b
+	}
+	b.refill(limit, now)
+
+	if b.tokens < 1 {
+		b.rejected++
+		wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return &LimitError{Budget: budget, RetryAfter: wait}
+	}
+	b.tokens--
+	b.allowed++
+	return nil
+}
+
+func (b *bucket) refill(limit Limit, now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	b.updated = now
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	}
+}
+
+// sweep раз в idleTTL удаляет клиентов, которые давно не присылали запросов:
+// их бюджеты к этому моменту всё равно полностью восстановились. Очистку выполняет
+// тот вызов, который первым заметил, что пора; шарды блокируются по очереди.
+func (l *Limiter) sweep(now time.Time) {
+	last := l.lastSweep.Load()
+	if now.UnixNano()-last < int64(l.idleTTL) || !l.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+	for i := range l.shards {
+		s := &l.shards[i]
+		s.mu.Lock()
+		for key, c := range s.clients {
+			if now.Sub(c.lastSeen) >= l.idleTTL {
+				delete(s.clients, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+type BucketState struct {
+	Tokens   float64 `json:"tokens"`
+	Allowed  uint64  `json:"allowed"`
+	Rejected uint64  `json:"rejected"`
+}
+
+type ClientState struct {
+	Key      string                 `json:"key"`
+	LastSeen time.Time              `json:"last_seen"`
+	Budgets  map[Budget]BucketState `json:"budgets"`
+}
+
+type State struct {
+	Limits  map[Budget]Limit `json:"limits"`
+	Clients []ClientState    `json:"clients"`
+}
+
+// Snapshot возвращает лимиты и текущие бюджеты всех известных клиентов,
+// отсортированных по числу отклонённых запросов.
+func (l *Limiter) Snapshot() State {
+	now := l.now()
+
+	state := State{
+		Limits:  make(map[Budget]Limit, len(l.limits)),
+		Clients: []ClientState{},
+	}
+	for budget, limit := range l.limits {
+		state.Limits[budget] = limit
+	}
+
+	for i := range l.shards {
+		s := &l.shards[i]
+		s.mu.Lock()
+		for key, c := range s.clients {
+			cs := ClientState{Key: key, LastSeen: c.lastSeen, Budgets: make(map[Budget]BucketState, len(c.buckets))}
+			for budget, b := range c.buckets {
+				b.refill(l.limits[budget], now)
+				cs.Budgets[budget] = BucketState{Tokens: math.Floor(b.tokens*100) / 100, Allowed: b.allowed, Rejected: b.rejected}
+			}
+			state.Clients = append(state.Clients, cs)
+		}
+		s.mu.Unlock()
+	}
+
+	sort.Slice(state.Clients, func(i, j int) bool {
+		ri, rj := state.Clients[i].rejected(), state.Clients[j].rejected()
+		if ri != rj {
+			return ri > rj
+		}
+		return state.Clients[i].Key < state.Clients[j].Key
+	})
+	return state
+}
+
+func (c ClientState) rejected() uint64 {
+	var n uint64
+	for _, b := range c.Budgets {
+		n += b.Rejected
+	}
+	return n
+}
+
+type contextKey struct{}
+
+type scope struct {
+	limiter *Limiter
+	key     string
+}
+
+// NewContext привязывает к контексту запроса лимитер и ключ клиента,
+// чтобы слой чтения заказов мог списывать бюджет по факту промаха или попадания в кэш.
+func NewContext(ctx context.Context, limiter *Limiter, key string) context.Context {
+	return context.WithValue(ctx, contextKey{}, scope{limiter: limiter, key: key})
+}
+
+// Charge списывает токен из бюджета клиента, привязанного к контексту.
+// Без привязанного лимитера (внутренние вызовы, консольные утилиты) ограничений нет.
+func Charge(ctx context.Context, budget Budget) error {
+	s, ok := ctx.Value(contextKey{}).(scope)
+	if !ok {
+		return nil
+	}
+	return s.limiter.Allow(s.key, budget)
+}
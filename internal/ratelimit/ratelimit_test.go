@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(cfg Config) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := New(cfg)
+	l.now = clock.Now
+	return l, clock
+}
+
+func TestLimiter_BurstAndRefill(t *testing.T) {
+	l, clock := newTestLimiter(Config{DB: Limit{Rate: 2, Burst: 3}})
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.Allow("a", BudgetDB))
+	}
+
+	err := l.Allow("a", BudgetDB)
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, BudgetDB, limitErr.Budget)
+	assert.Equal(t, 500*time.Millisecond, limitErr.RetryAfter)
+
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	assert.NoError(t, l.Allow("a", BudgetDB))
+	assert.Error(t, l.Allow("a", BudgetDB))
+
+	// Бюджет не копится сверх burst.
+	clock.now = clock.now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.Allow("a", BudgetDB))
+	}
+	assert.Error(t, l.Allow("a", BudgetDB))
+}
+
+func TestLimiter_SeparateBudgetsAndClients(t *testing.T) {
+	l, _ := newTestLimiter(Config{Cache: Limit{Rate: 1, Burst: 2}, DB: Limit{Rate: 1, Burst: 1}})
+
+	require.NoError(t, l.Allow("a", BudgetDB))
+	assert.Error(t, l.Allow("a", BudgetDB))
+
+	assert.NoError(t, l.Allow("a", BudgetCache), "cache budget must not be spent by DB requests")
+	assert.NoError(t, l.Allow("b", BudgetDB), "clients must not share budgets")
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l, _ := newTestLimiter(Config{DB: Limit{Rate: 1, Burst: 1}})
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, l.Allow("a", BudgetCache))
+	}
+	assert.Empty(t, l.Snapshot().Clients)
+}
+
+func TestLimiter_ZeroBurst(t *testing.T) {
+	l, _ := newTestLimiter(Config{DB: Limit{Rate: 0.5}})
+
+	assert.NoError(t, l.Allow("a", BudgetDB))
+	assert.Error(t, l.Allow("a", BudgetDB))
+}
+
+func TestLimiter_ForgetsIdleClients(t *testing.T) {
+	l, clock := newTestLimiter(Config{DB: Limit{Rate: 1, Burst: 1}, IdleTTL: time.Minute})
+
+	require.NoError(t, l.Allow("a", BudgetDB))
+	clock.now = clock.now.Add(2 * time.Minute)
+	require.NoError(t, l.Allow("b", BudgetDB))
+
+	clients := l.Snapshot().Clients
+	require.Len(t, clients, 1)
+	assert.Equal(t, "b", clients[0].Key)
+}
+
+func TestLimiter_Snapshot(t *testing.T) {
+	l, _ := newTestLimiter(Config{Cache: Limit{Rate: 10, Burst: 5}, DB: Limit{Rate: 1, Burst: 1}})
+
+	require.NoError(t, l.Allow("quiet", BudgetCache))
+	require.NoError(t, l.Allow("noisy", BudgetDB))
+	require.Error(t, l.Allow("noisy", BudgetDB))
+	require.Error(t, l.Allow("noisy", BudgetDB))
+
+	state := l.Snapshot()
+	assert.Equal(t, Limit{Rate: 10, Burst: 5}, state.Limits[BudgetCache])
+	require.Len(t, state.Clients, 2)
+	assert.Equal(t, "noisy", state.Clients[0].Key)
+	assert.Equal(t, BucketState{Tokens: 0, Allowed: 1, Rejected: 2}, state.Clients[0].Budgets[BudgetDB])
+	assert.Equal(t, BucketState{Tokens: 4, Allowed: 1}, state.Clients[1].Budgets[BudgetCache])
+}
+
+func TestCharge(t *testing.T) {
+	assert.NoError(t, Charge(context.Background(), BudgetDB), "no limiter in context means no limit")
+
+	l, _ := newTestLimiter(Config{DB: Limit{Rate: 1, Burst: 1}})
+	ctx := NewContext(context.Background(), l, "a")
+	assert.NoError(t, Charge(ctx, BudgetDB))
+	assert.Error(t, Charge(ctx, BudgetDB))
+}
+
+func TestLimitError_RetryAfterSeconds(t *testing.T) {
+	for retryAfter, want := range map[time.Duration]int{
+		0:                       1,
+		100 * time.Millisecond:  1,
+		time.Second:             1,
+		1500 * time.Millisecond: 2,
+		3 * time.Second:         3,
+	} {
+		err := &LimitError{Budget: BudgetDB, RetryAfter: retryAfter}
+		assert.Equal(t, want, err.RetryAfterSeconds(), retryAfter)
+	}
+}
+
+func TestLimiter_ConcurrentClients(t *testing.T) {
+	l, _ := newTestLimiter(Config{Cache: Limit{Rate: 1, Burst: 10}})
+
+	const clients = 50
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("client-%d", i)
+			for j := 0; j < 10; j++ {
+				assert.NoError(t, l.Allow(key, BudgetCache))
+			}
+			assert.Error(t, l.Allow(key, BudgetCache))
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, l.Snapshot().Clients, clients)
+}
@@ -0,0 +1,93 @@
+// Package redact готовит заказы к выдаче клиенту: персональные данные доставки
+// показываются, маскируются или убираются в зависимости от роли клиента.
+package redact
+
+import (
+	"strings"
+
+	"l0/internal/auth"
+	"l0/internal/models"
+	"l0/internal/utils"
+)
+
+type treatment int
+
+const (
+	hide treatment = iota
+	mask
+	show
+)
+
+// policy - что роль видит в каждом персональном поле доставки (поля с тегом pii).
+type policy struct {
+	name, phone, zip, address, email treatment
+}
+
+var policies = map[auth.Role]policy{
+	auth.RoleAdmin:   {name: show, phone: show, zip: show, address: show, email: show},
+	auth.RoleSupport: {name: show, phone: mask, zip: hide, address: hide, email: mask},
+	// analytics и любые неизвестные роли не видят персональных данных.
+	auth.RoleAnalytics: {},
+}
+
+func policyFor(role auth.Role) policy {
+	return policies[role]
+}
+
+// Order возвращает копию заказа с доставкой, подготовленной для роли. Исходный заказ
+// (например, из кэша) не меняется.
+func Order(order models.Order, role auth.Role) models.Order {
+	order.Delivery = Delivery(order.Delivery, role)
+	return order
+}
+
+// Orders подготавливает список заказов, не изменяя исходный срез.
+func Orders(orders []models.Order, role auth.Role) []models.Order {
+	if orders == nil {
+		return nil
+	}
+	result := make([]models.Order, len(orders))
+	for i, order := range orders {
+		result[i] = Order(order, role)
+	}
+	return result
+}
+
+func Delivery(d models.Delivery, role auth.Role) models.Delivery {
+	p := policyFor(role)
+	d.Name = apply(d.Name, p.name, utils.MaskValue)
+	d.Phone = apply(d.Phone, p.phone, MaskPhone)
+	d.Zip = apply(d.Zip, p.zip, utils.MaskValue)
+	d.Address = apply(d.Address, p.address, utils.MaskValue)
+	d.Email = apply(d.Email, p.email, MaskEmail)
+	return d
+}
+
+func apply(value string, t treatment, maskFn func(string) string) string {
+	switch t {
+	case show:
+		return value
+	case mask:
+		return maskFn(value)
+	default:
+		return ""
+	}
+}
+
+// MaskPhone оставляет код страны и последние четыре цифры: +79161234567 → +7******4567.
+func MaskPhone(phone string) string {
+	runes := []rune(phone)
+	if len(runes) <= 6 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:2]) + strings.Repeat("*", len(runes)-6) + string(runes[len(runes)-4:])
+}
+
+// MaskEmail маскирует имя ящика и оставляет домен: test.user@gmail.com → te*****er@gmail.com.
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return utils.MaskValue(email)
+	}
+	return utils.MaskValue(local) + "@" + domain
+}
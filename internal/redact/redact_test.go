@@ -0,0 +1,76 @@
+package redact
+
+import (
+	"testing"
+
+	"l0/internal/auth"
+	"l0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testOrder() models.Order {
+	return models.Order{
+		OrderUID: "o-1",
+		Delivery: models.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Items: []models.Item{{ChrtID: 1}},
+	}
+}
+
+func TestOrder_Admin(t *testing.T) {
+	order := testOrder()
+	assert.Equal(t, order, Order(order, auth.RoleAdmin))
+}
+
+func TestOrder_Support(t *testing.T) {
+	d := Order(testOrder(), auth.RoleSupport).Delivery
+
+	assert.Equal(t, "Test Testov", d.Name)
+	assert.Equal(t, "+9*****0000", d.Phone)
+	assert.Equal(t, "****@gmail.com", d.Email)
+	assert.Empty(t, d.Address)
+	assert.Empty(t, d.Zip)
+	assert.Equal(t, "Kiryat Mozkin", d.City)
+	assert.Equal(t, "Kraiot", d.Region)
+}
+
+func TestOrder_Analytics(t *testing.T) {
+	d := Order(testOrder(), auth.RoleAnalytics).Delivery
+
+	assert.Equal(t, models.Delivery{City: "Kiryat Mozkin", Region: "Kraiot"}, d)
+}
+
+func TestOrder_UnknownRoleSeesNoPII(t *testing.T) {
+	d := Order(testOrder(), auth.Role("intern")).Delivery
+
+	assert.Empty(t, d.Name)
+	assert.Empty(t, d.Phone)
+	assert.Empty(t, d.Email)
+}
+
+func TestOrders_DoesNotModifySource(t *testing.T) {
+	source := []models.Order{testOrder()}
+
+	redacted := Orders(source, auth.RoleAnalytics)
+	assert.Empty(t, redacted[0].Delivery.Phone)
+	assert.Equal(t, "+9720000000", source[0].Delivery.Phone)
+	assert.Nil(t, Orders(nil, auth.RoleAnalytics))
+}
+
+func TestMaskPhone(t *testing.T) {
+	assert.Equal(t, "+7******4567", MaskPhone("+79161234567"))
+	assert.Equal(t, "*****", MaskPhone("12345"))
+}
+
+func TestMaskEmail(t *testing.T) {
+	assert.Equal(t, "te*****er@gmail.com", MaskEmail("test.user@gmail.com"))
+	assert.Equal(t, "no********il", MaskEmail("not-an-email"))
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/logging"
+	"l0/internal/models"
+	"l0/internal/ratelimit"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Orders - общая логика чтения заказов для HTTP и gRPC API:
+// сначала кэш, затем PostgreSQL с сохранением найденного в кэш.
+type Orders struct {
+	cache cache.Cache
+	db    db.Database
+	// loads объединяет одновременные промахи по одному UID в один запрос к БД.
+	loads singleflight.Group
+}
+
+func NewOrders(cache cache.Cache, db db.Database) *Orders {
+	return &Orders{cache: cache, db: db}
+}
+
+// Get ищет заказ сначала в кэше, затем в БД, и кладёт найденный в БД заказ в кэш.
+// Если заказа нет в БД, возвращается db.ErrOrderNotFound, а кэш ненадолго
+// запоминает это, чтобы повторные запросы не доходили до БД.
+// Попадание в кэш и обращение к БД списываются из разных бюджетов клиента;
+// при исчерпанном бюджете возвращается *ratelimit.LimitError.
+func (s *Orders) Get(ctx context.Context, uid string) (*models.Order, error) {
+	order, exists := s.cache.Get(uid)
+	missing := !exists && s.cache.Missing(uid)
+	logging.RecordCacheLookup(ctx, exists || missing)
+	if exists || missing {
+		if err := ratelimit.Charge(ctx, ratelimit.BudgetCache); err != nil {
+			return nil, err
+		}
+		if missing {
+			return nil, db.ErrOrderNotFound
+		}
+		return &order, nil
+	}
+
+	if err := ratelimit.Charge(ctx, ratelimit.BudgetDB); err != nil {
+		return nil, err
+	}
+	return s.load(ctx, uid)
+}
+
+// load загружает заказ из БД так, что одновременно по одному UID идёт не больше
+// одного запроса: остальные вызовы ждут его результат. Запрос не прерывается, если
+// отменён контекст вызова, который его начал, - результат нужен и остальным.
+func (s *Orders) load(ctx context.Context, uid string) (*models.Order, error) {
+	ch := s.loads.DoChan(uid, func() (interface{}, error) {
+		// Заказ мог попасть в кэш, пока этот вызов шёл от промаха к загрузке.
+		if order, ok := s.cache.Peek(uid); ok {
+			return &order, nil
+		}
+		loaded, err := s.db.GetOrder(context.WithoutCancel(ctx), uid)
+		if errors.Is(err, db.ErrOrderNotFound) {
+			s.cache.MarkMissing(uid)
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.cache.Set(uid, *loaded)
+		return loaded, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		// Каждый вызов получает свою копию заказа вместе с товарами, чтобы его изменения
+		// не видели другие вызовы и заказ в кэше.
+		order := *res.Val.(*models.Order)
+		order.Items = slices.Clone(order.Items)
+		return &order, nil
+	}
+}
+
+// MaxBatchGet - наибольшее число UID в одном пакетном запросе.
+const MaxBatchGet = 1000
+
+// BatchGet отдаёт попавшие в кэш заказы из кэша, а все промахи загружает из БД
+// одним запросом и кладёт в кэш. UID, которых недавно не было в БД, не запрашиваются.
+// Заказы возвращаются в порядке запроса (повторяющиеся UID - один раз),
+// ненайденные UID - отдельным списком.
+func (s *Orders) BatchGet(ctx context.Context, uids []string) ([]models.Order, []string, error) {
+	found := make(map[string]models.Order, len(uids))
+	var misses []string
+
+	seen := make(map[string]struct{}, len(uids))
+	unique := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		unique = append(unique, uid)
+
+		order, ok := s.cache.Get(uid)
+		missing := !ok && s.cache.Missing(uid)
+		logging.RecordCacheLookup(ctx, ok || missing)
+		switch {
+		case ok:
+			found[uid] = order
+		case !missing:
+			misses = append(misses, uid)
+		}
+	}
+
+	// Пакет промахов загружается одним запросом, поэтому и списывается как один запрос к БД.
+	budget := ratelimit.BudgetCache
+	if len(misses) > 0 {
+		budget = ratelimit.BudgetDB
+	}
+	if err := ratelimit.Charge(ctx, budget); err != nil {
+		return nil, nil, err
+	}
+
+	if len(misses) > 0 {
+		loaded, err := s.db.GetOrders(ctx, misses)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, order := range loaded {
+			s.cache.Set(order.OrderUID, order)
+			found[order.OrderUID] = order
+		}
+		for _, uid := range misses {
+			if _, ok := found[uid]; !ok {
+				s.cache.MarkMissing(uid)
+			}
+		}
+	}
+
+	orders := make([]models.Order, 0, len(found))
+	var notFound []string
+	for _, uid := range unique {
+		if order, ok := found[uid]; ok {
+			orders = append(orders, order)
+		} else {
+			notFound = append(notFound, uid)
+		}
+	}
+	return orders, notFound, nil
+}
+
+func (s *Orders) List(ctx context.Context, params db.ListOrdersParams) (*db.OrderPage, error) {
+	if err := ratelimit.Charge(ctx, ratelimit.BudgetDB); err != nil {
+		return nil, err
+	}
+	return s.db.ListOrders(ctx, params)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitingContext сообщает в waiting, когда вызов начинает ждать загрузку:
+// Orders.load обращается к Done только после того, как вызов присоединился к singleflight.
+type waitingContext struct {
+	context.Context
+	waiting chan<- struct{}
+}
+
+func (c waitingContext) Done() <-chan struct{} {
+	c.waiting <- struct{}{}
+	return c.Context.Done()
+}
+
+func TestOrders_GetCoalescesConcurrentMisses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDatabase(ctrl)
+	orderCache := cache.NewCache()
+	orders := NewOrders(orderCache, mockDB)
+
+	release := make(chan struct{})
+	mockDB.EXPECT().GetOrder(gomock.Any(), "hot-1").DoAndReturn(
+		func(context.Context, string) (*models.Order, error) {
+			<-release
+			return &models.Order{OrderUID: "hot-1", Items: []models.Item{{Name: "Item"}}}, nil
+		}).Times(1)
+
+	const callers = 10
+	var (
+		wg      sync.WaitGroup
+		waiting = make(chan struct{}, callers)
+		results = make([]*models.Order, callers)
+		errs    = make([]error, callers)
+	)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = orders.Get(waitingContext{context.Background(), waiting}, "hot-1")
+		}()
+	}
+	for i := 0; i < callers; i++ {
+		<-waiting
+	}
+	close(release)
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "hot-1", results[i].OrderUID)
+	}
+	assert.NotSame(t, results[0], results[1], "callers get their own copies")
+
+	results[0].Items[0].Name = "Changed"
+	assert.Equal(t, "Item", results[1].Items[0].Name, "items are not shared between callers")
+	cached, ok := orderCache.Peek("hot-1")
+	require.True(t, ok)
+	assert.Equal(t, "Item", cached.Items[0].Name, "items are not shared with the cache")
+}
+
+func TestOrders_GetSharesErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDatabase(ctrl)
+	orders := NewOrders(cache.NewCache(), mockDB)
+
+	dbErr := errors.New("connection refused")
+	release := make(chan struct{})
+	mockDB.EXPECT().GetOrder(gomock.Any(), "broken").DoAndReturn(
+		func(context.Context, string) (*models.Order, error) {
+			<-release
+			return nil, dbErr
+		}).Times(1)
+
+	const callers = 5
+	var wg sync.WaitGroup
+	waiting := make(chan struct{}, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := orders.Get(waitingContext{context.Background(), waiting}, "broken")
+			assert.ErrorIs(t, err, dbErr)
+		}()
+	}
+	for i := 0; i < callers; i++ {
+		<-waiting
+	}
+	close(release)
+	wg.Wait()
+}
+
+func TestOrders_GetCancelledCallerDoesNotAbortLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDatabase(ctrl)
+	orders := NewOrders(cache.NewCache(), mockDB)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	mockDB.EXPECT().GetOrder(gomock.Any(), "slow-1").DoAndReturn(
+		func(ctx context.Context, uid string) (*models.Order, error) {
+			close(started)
+			<-release
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return &models.Order{OrderUID: uid}, nil
+		}).Times(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := orders.Get(ctx, "slow-1")
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan *models.Order, 1)
+	go func() {
+		order, err := orders.Get(context.Background(), "slow-1")
+		assert.NoError(t, err)
+		second <- order
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	close(release)
+	assert.Equal(t, "slow-1", (<-second).OrderUID)
+}
+
+func TestOrders_GetRemembersMissingOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDatabase(ctrl)
+	orders := NewOrders(cache.New(cache.Config{NegativeTTL: time.Minute}), mockDB)
+
+	mockDB.EXPECT().GetOrder(gomock.Any(), "bogus").Return(nil, db.ErrOrderNotFound).Times(1)
+	for i := 0; i < 3; i++ {
+		_, err := orders.Get(context.Background(), "bogus")
+		assert.ErrorIs(t, err, db.ErrOrderNotFound)
+	}
+
+	mockDB.EXPECT().GetOrder(gomock.Any(), "flaky").Return(nil, errors.New("connection refused")).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := orders.Get(context.Background(), "flaky")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, db.ErrOrderNotFound, "failures are not cached")
+	}
+}
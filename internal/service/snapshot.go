@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/logging"
+	"l0/internal/models"
+)
+
+// snapshotSkew - запас при сверке с БД: заказы, изменённые незадолго до снимка,
+// перечитываются на случай расхождения часов и записей, не успевших попасть в снимок.
+const snapshotSkew = time.Minute
+
+// Snapshotter сохраняет кэш в файл и восстанавливает его при старте, сверяясь с PostgreSQL.
+type Snapshotter struct {
+	cache cache.Cache
+	db    db.Database
+	path  string
+	now   func() time.Time
+}
+
+func NewSnapshotter(cache cache.Cache, db db.Database, path string) *Snapshotter {
+	return &Snapshotter{cache: cache, db: db, path: path, now: time.Now}
+}
+
+// Save записывает текущее содержимое кэша в файл снимка.
+func (s *Snapshotter) Save(ctx context.Context) error {
+	start := time.Now()
+	snap := cache.TakeSnapshot(s.cache, s.now())
+	if err := cache.WriteSnapshot(s.path, snap); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info("Cache snapshot saved", "orders", len(snap.Orders), "path", s.path, "duration", time.Since(start))
+	return nil
+}
+
+// Run сохраняет снимок каждые interval, пока не отменён ctx.
+func (s *Snapshotter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Save(ctx); err != nil {
+				logging.FromContext(ctx).Error("Failed to save cache snapshot", "error", err)
+			}
+		}
+	}
+}
+
+// Restore загружает снимок в кэш и перечитывает из БД заказы, созданные или изменённые
+// после него. Возвращает число заказов в снимке. Заказы, которые уже успели попасть
+// в кэш из Kafka, снимком не перезаписываются. Если сверка с БД не удалась, кэш очищается:
+// без неё в кэше могут остаться устаревшие статусы.
+func (s *Snapshotter) Restore(ctx context.Context) (int, error) {
+	start := time.Now()
+	snap, err := cache.ReadSnapshot(s.path)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, order := range snap.Orders {
+		if _, ok := s.cache.Peek(order.OrderUID); !ok {
+			s.cache.Set(order.OrderUID, order)
+		}
+	}
+
+	params := db.ListOrdersParams{Sort: db.SortByDate}
+	params.Filter.UpdatedFrom = snap.TakenAt.Add(-snapshotSkew)
+	reconciled := 0
+	err = s.db.StreamOrders(ctx, params, func(order models.Order) error {
+		s.cache.Set(order.OrderUID, order)
+		reconciled++
+		return nil
+	})
+	if err != nil {
+		s.cache.Clear()
+		return 0, fmt.Errorf("failed to reconcile cache snapshot with DB: %v", err)
+	}
+
+	logging.FromContext(ctx).Info("Cache restored from snapshot",
+		"orders", len(snap.Orders), "reconciled", reconciled, "taken_at", snap.TakenAt, "duration", time.Since(start))
+	return len(snap.Orders), nil
+}
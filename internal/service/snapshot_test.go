@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotter_SaveAndRestore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDatabase(ctrl)
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	takenAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	source := cache.NewCache()
+	source.Set("o-1", models.Order{OrderUID: "o-1", Status: "created"})
+	source.Set("o-2", models.Order{OrderUID: "o-2", Status: "created"})
+	saver := NewSnapshotter(source, mockDB, path)
+	saver.now = func() time.Time { return takenAt }
+	require.NoError(t, saver.Save(context.Background()))
+
+	restored := cache.NewCache()
+	restored.Set("o-2", models.Order{OrderUID: "o-2", Status: "paid"})
+	mockDB.EXPECT().StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params db.ListOrdersParams, fn func(models.Order) error) error {
+			assert.True(t, params.Filter.UpdatedFrom.Before(takenAt))
+			return fn(models.Order{OrderUID: "o-3", Status: "created"})
+		})
+
+	loaded, err := NewSnapshotter(restored, mockDB, path).Restore(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded)
+
+	order, ok := restored.Peek("o-2")
+	require.True(t, ok)
+	assert.Equal(t, "paid", order.Status, "snapshot does not overwrite fresher orders")
+	_, ok = restored.Peek("o-1")
+	assert.True(t, ok)
+	_, ok = restored.Peek("o-3")
+	assert.True(t, ok, "orders newer than the snapshot are loaded from DB")
+}
+
+func TestSnapshotter_RestoreClearsCacheWhenReconcileFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDatabase(ctrl)
+	path := filepath.Join(t.TempDir(), "cache.snap")
+	require.NoError(t, cache.WriteSnapshot(path, cache.Snapshot{
+		TakenAt: time.Now(),
+		Orders:  []models.Order{{OrderUID: "stale-1"}},
+	}))
+
+	orderCache := cache.NewCache()
+	mockDB.EXPECT().StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).Return(assert.AnError)
+
+	_, err := NewSnapshotter(orderCache, mockDB, path).Restore(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, orderCache.GetAll())
+}
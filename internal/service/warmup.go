@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/logging"
+	"l0/internal/models"
+)
+
+// ErrWarmupRunning возвращается при попытке запустить прогрев, пока идёт предыдущий.
+var ErrWarmupRunning = errors.New("cache warm-up is already running")
+
+// WarmupWindow - какие заказы загружать в кэш: не больше Limit самых новых,
+// созданных не раньше чем Since назад. Нулевое значение снимает ограничение,
+// но больше, чем вмещает кэш (Stats().MaxEntries), не загружается.
+type WarmupWindow struct {
+	Limit int
+	Since time.Duration
+	// Flush очищает кэш перед загрузкой.
+	Flush bool
+}
+
+// DefaultWarmupWindow - окно ручного прогрева, если параметры не заданы.
+var DefaultWarmupWindow = WarmupWindow{Limit: 30}
+
+// WarmupStatus - состояние текущего или последнего прогрева.
+type WarmupStatus struct {
+	Running    bool
+	Window     WarmupWindow
+	Loaded     int
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Err        error
+}
+
+// Warmer загружает заказы из PostgreSQL в кэш. Одновременно выполняется не больше одного прогрева.
+type Warmer struct {
+	cache cache.Cache
+	db    db.Database
+
+	mu     sync.Mutex
+	status WarmupStatus
+	now    func() time.Time
+}
+
+func NewWarmer(cache cache.Cache, db db.Database) *Warmer {
+	return &Warmer{cache: cache, db: db, now: time.Now}
+}
+
+// Run загружает окно заказов в кэш и возвращает их число.
+func (w *Warmer) Run(ctx context.Context, window WarmupWindow) (int, error) {
+	if err := w.begin(window); err != nil {
+		return 0, err
+	}
+	return w.load(ctx, window)
+}
+
+// Start запускает прогрев в фоне; ход прогрева виден в Status.
+// ctx не должен отменяться вместе с запросом, который запустил прогрев.
+func (w *Warmer) Start(ctx context.Context, window WarmupWindow) error {
+	if err := w.begin(window); err != nil {
+		return err
+	}
+	go func() {
+		_, _ = w.load(ctx, window)
+	}()
+	return nil
+}
+
+func (w *Warmer) Status() WarmupStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+func (w *Warmer) begin(window WarmupWindow) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status.Running {
+		return ErrWarmupRunning
+	}
+	w.status = WarmupStatus{Running: true, Window: window, StartedAt: w.now()}
+	return nil
+}
+
+func (w *Warmer) load(ctx context.Context, window WarmupWindow) (int, error) {
+	start := time.Now()
+	logger := logging.FromContext(ctx)
+
+	if window.Flush {
+		logger.Info("Cache flushed before warm-up", "orders", w.cache.Clear())
+	}
+
+	params := db.ListOrdersParams{Sort: db.SortByDate, Desc: true, Limit: window.Limit}
+	// Заказы идут от новых к старым, поэтому сверх ёмкости кэша старые вытесняли бы
+	// только что загруженные новые.
+	if capacity := w.cache.Stats().MaxEntries; capacity > 0 && (params.Limit == 0 || params.Limit > capacity) {
+		params.Limit = capacity
+	}
+	if window.Since > 0 {
+		params.Filter.CreatedFrom = w.now().Add(-window.Since)
+	}
+
+	loaded := 0
+	err := w.db.StreamOrders(ctx, params, func(order models.Order) error {
+		w.cache.Set(order.OrderUID, order)
+		loaded++
+		w.mu.Lock()
+		w.status.Loaded = loaded
+		w.mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to load orders into cache: %v", err)
+	}
+
+	w.mu.Lock()
+	w.status.Running = false
+	w.status.Loaded = loaded
+	w.status.FinishedAt = w.now()
+	w.status.Err = err
+	w.mu.Unlock()
+
+	if err != nil {
+		return loaded, err
+	}
+	logger.Info("Cache warm-up completed", "orders", loaded, "duration", time.Since(start))
+	return loaded, nil
+}
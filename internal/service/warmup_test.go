@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"l0/internal/cache"
+	"l0/internal/db"
+	"l0/internal/models"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmer_RunLimitsLoadToCacheCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockDB := db.NewMockDatabase(ctrl)
+	capacity := 64
+	warmer := NewWarmer(cache.New(cache.Config{MaxEntries: capacity}), mockDB)
+
+	mockDB.EXPECT().StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, params db.ListOrdersParams, fn func(models.Order) error) error {
+			assert.Equal(t, capacity, params.Limit, "all orders are capped by cache capacity")
+			assert.True(t, params.Desc, "newest orders are loaded first")
+			return fn(models.Order{OrderUID: "warm-1"})
+		})
+
+	loaded, err := warmer.Run(context.Background(), WarmupWindow{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+
+	status := warmer.Status()
+	assert.False(t, status.Running)
+	assert.Equal(t, 1, status.Loaded)
+	assert.False(t, status.FinishedAt.IsZero())
+}
+
+func TestWarmer_RunWindowToQuery(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		capacity        int
+		window          WarmupWindow
+		wantLimit       int
+		wantCreatedFrom time.Time
+	}{
+		{name: "last", capacity: 100, window: WarmupWindow{Limit: 30}, wantLimit: 30},
+		{name: "last above capacity", capacity: 10, window: WarmupWindow{Limit: 30}, wantLimit: 10},
+		{name: "recent", capacity: 100, window: WarmupWindow{Since: 24 * time.Hour}, wantLimit: 100, wantCreatedFrom: now.Add(-24 * time.Hour)},
+		{name: "all", capacity: 100, window: WarmupWindow{}, wantLimit: 100},
+		{name: "all without capacity", window: WarmupWindow{}, wantLimit: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockDB := db.NewMockDatabase(ctrl)
+			warmer := NewWarmer(cache.New(cache.Config{MaxEntries: tt.capacity}), mockDB)
+			warmer.now = func() time.Time { return now }
+
+			mockDB.EXPECT().StreamOrders(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, params db.ListOrdersParams, _ func(models.Order) error) error {
+					assert.Equal(t, tt.wantLimit, params.Limit)
+					assert.True(t, tt.wantCreatedFrom.Equal(params.Filter.CreatedFrom), "created_from %s", params.Filter.CreatedFrom)
+					return nil
+				})
+
+			_, err := warmer.Run(context.Background(), tt.window)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package utils
+
+import "strings"
+
+// MaskValue скрывает середину строки, оставляя по краям не больше двух символов.
+// Короткие значения маскируются полностью.
+func MaskValue(value string) string {
+	runes := []rune(value)
+	switch {
+	case len(runes) == 0:
+		return ""
+	case len(runes) <= 4:
+		return strings.Repeat("*", len(runes))
+	case len(runes) <= 8:
+		return string(runes[:1]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1:])
+	default:
+		return string(runes[:2]) + strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-2:])
+	}
+}
@@ -1,6 +1,13 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"l0/internal/models"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -8,20 +15,192 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	validate.RegisterTagNameFunc(jsonFieldName)
+	if err := validate.RegisterValidation("present", validatePresent, true); err != nil {
+		panic(err)
+	}
 }
 
+// ValidationError описывает одно нарушение правил валидации.
+// Path указывается в терминах JSON (items[3].price), Value маскируется для персональных данных.
+type ValidationError struct {
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidateStruct проверяет структуру s. Ошибки полей запоминают тип s,
+// чтобы GetValidationErrors нашла теги pii в той структуре, которая проверялась.
 func ValidateStruct(s interface{}) error {
-	return validate.Struct(s)
+	err := validate.Struct(s)
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return &structValidationError{root: reflect.TypeOf(s), errs: validationErrors}
+	}
+	return err
+}
+
+// structValidationError - ошибки валидатора вместе с типом проверенной структуры.
+type structValidationError struct {
+	root reflect.Type
+	errs validator.ValidationErrors
+}
+
+func (e *structValidationError) Error() string {
+	return e.errs.Error()
+}
+
+func (e *structValidationError) Unwrap() error {
+	return e.errs
+}
+
+// validatePresent проверяет, что поле было передано во входном JSON.
+// В отличие от required, явно переданный ноль считается допустимым значением.
+func validatePresent(fl validator.FieldLevel) bool {
+	parent := fl.Parent()
+	tracker, ok := parent.Interface().(models.PresenceTracker)
+	if !ok {
+		return true
+	}
+
+	field, ok := parent.Type().FieldByName(fl.StructFieldName())
+	if !ok {
+		return true
+	}
+	return tracker.Has(jsonFieldName(field))
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// GetValidationErrors превращает ошибку валидатора в список ошибок с JSON-путями.
+// Для ошибок, не связанных с валидацией полей, возвращает nil.
+func GetValidationErrors(err error) []ValidationError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	// Без типа корневой структуры тег pii не найти, поэтому значения маскируются все.
+	var root reflect.Type
+	var structErr *structValidationError
+	if errors.As(err, &structErr) {
+		root = structErr.root
+	}
+
+	result := make([]ValidationError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		value := formatValue(fieldError.Value())
+		if root == nil || isPIIField(root, fieldError) {
+			value = MaskValue(value)
+		}
+
+		result = append(result, ValidationError{
+			Path:    jsonPath(fieldError.Namespace()),
+			Rule:    fieldError.Tag(),
+			Value:   value,
+			Message: validationMessage(fieldError),
+		})
+	}
+	return result
+}
+
+// PrefixPaths добавляет префикс к путям ошибок, например индекс заказа в пакетном запросе.
+func PrefixPaths(prefix string, errs []ValidationError) []ValidationError {
+	for i := range errs {
+		if strings.HasPrefix(errs[i].Path, "[") {
+			errs[i].Path = prefix + errs[i].Path
+		} else {
+			errs[i].Path = prefix + "." + errs[i].Path
+		}
+	}
+	return errs
 }
 
-func GetValidationErrors(err error) map[string]string {
-	errors := make(map[string]string)
+// jsonPath отрезает имя корневой структуры: "Order.items[3].price" -> "items[3].price".
+func jsonPath(namespace string) string {
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		return rest
+	}
+	return namespace
+}
+
+// isPIIField ищет исходное поле в структуре root по StructNamespace и проверяет тег pii.
+func isPIIField(root reflect.Type, fieldError validator.FieldError) bool {
+	segments := strings.Split(fieldError.StructNamespace(), ".")
+	if len(segments) < 2 {
+		return false
+	}
+
+	t := root
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if segments[0] != t.Name() {
+		return false
+	}
 
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
-		for _, fieldError := range validationErrors {
-			errors[fieldError.Field()] = fieldError.Tag()
+	for _, segment := range segments[1:] {
+		name, _, _ := strings.Cut(segment, "[")
+		for t.Kind() == reflect.Slice || t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		field, ok := t.FieldByName(name)
+		if !ok {
+			return false
+		}
+		if field.Tag.Get("pii") == "true" {
+			return true
 		}
+		t = field.Type
 	}
+	return false
+}
 
-	return errors
+func formatValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Struct:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func validationMessage(fieldError validator.FieldError) string {
+	isCollection := fieldError.Kind() == reflect.Slice || fieldError.Kind() == reflect.Map
+
+	switch fieldError.Tag() {
+	case "required":
+		return "field is required"
+	case "present":
+		return "field must be present, zero is allowed"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if isCollection {
+			return fmt.Sprintf("must contain at least %s element(s)", fieldError.Param())
+		}
+		return fmt.Sprintf("must be greater than or equal to %s", fieldError.Param())
+	case "max":
+		if isCollection {
+			return fmt.Sprintf("must contain at most %s element(s)", fieldError.Param())
+		}
+		return fmt.Sprintf("must be less than or equal to %s", fieldError.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(fieldError.Param()), ", "))
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", fieldError.Tag())
+	}
 }
@@ -1,12 +1,14 @@
 package utils
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"l0/internal/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidateStruct_ValidOrder(t *testing.T) {
@@ -67,5 +69,209 @@ func TestValidateStruct_InvalidOrder(t *testing.T) {
 	assert.Error(t, err, "Invalid order should return validation errors")
 
 	validationErrors := GetValidationErrors(err)
-	assert.Contains(t, validationErrors, "OrderUID", "OrderUID should be required")
+	orderUID, ok := findValidationError(validationErrors, "order_uid")
+	require.True(t, ok, "order_uid should be required")
+	assert.Equal(t, "required", orderUID.Rule)
+	assert.NotEmpty(t, orderUID.Message)
+}
+
+func findValidationError(errs []ValidationError, path string) (ValidationError, bool) {
+	for _, e := range errs {
+		if e.Path == path {
+			return e, true
+		}
+	}
+	return ValidationError{}, false
+}
+
+func ruleAt(errs []ValidationError, path string) string {
+	e, _ := findValidationError(errs, path)
+	return e.Rule
+}
+
+const promoOrderJSON = `{
+	"order_uid": "promo-1",
+	"track_number": "WBILPROMO",
+	"entry": "WBIL",
+	"delivery": {
+		"name": "Test Testov",
+		"phone": "+9720000000",
+		"zip": "2639809",
+		"city": "Kiryat Mozkin",
+		"address": "Ploshad Mira 15",
+		"region": "Kraiot",
+		"email": "test@gmail.com"
+	},
+	"payment": {
+		"transaction": "promo-1",
+		"request_id": "",
+		"currency": "USD",
+		"provider": "wbpay",
+		"amount": 0,
+		"payment_dt": 1637907727,
+		"bank": "alpha",
+		"delivery_cost": 0,
+		"goods_total": 0,
+		"custom_fee": 0
+	},
+	"items": [
+		{
+			"chrt_id": 9934930,
+			"track_number": "WBILPROMO",
+			"price": 0,
+			"rid": "ab4219087a764ae0btest",
+			"name": "Gift",
+			"sale": 100,
+			"size": "0",
+			"total_price": 0,
+			"nm_id": 2389212,
+			"brand": "Vivienne Sabo",
+			"status": 0
+		}
+	],
+	"locale": "en",
+	"internal_signature": "",
+	"customer_id": "test",
+	"delivery_service": "meest",
+	"shardkey": "9",
+	"sm_id": 0,
+	"date_created": "2021-11-26T06:22:19Z",
+	"oof_shard": "1"
+}`
+
+func decodePromoOrder(t *testing.T, mutate func(map[string]any)) models.Order {
+	t.Helper()
+
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal([]byte(promoOrderJSON), &raw))
+	if mutate != nil {
+		mutate(raw)
+	}
+
+	data, err := json.Marshal(raw)
+	require.NoError(t, err)
+
+	var order models.Order
+	require.NoError(t, json.Unmarshal(data, &order))
+	return order
+}
+
+func TestValidateStruct_ZeroPricedPromoItem(t *testing.T) {
+	order := decodePromoOrder(t, nil)
+
+	err := ValidateStruct(order)
+	assert.NoError(t, err, "Explicit zero values must be accepted")
+}
+
+func TestValidateStruct_MissingNumericField(t *testing.T) {
+	order := decodePromoOrder(t, func(raw map[string]any) {
+		item := raw["items"].([]any)[0].(map[string]any)
+		delete(item, "price")
+		delete(raw, "sm_id")
+	})
+
+	err := ValidateStruct(order)
+	assert.Error(t, err)
+
+	validationErrors := GetValidationErrors(err)
+	assert.Equal(t, "present", ruleAt(validationErrors, "items[0].price"))
+	assert.Equal(t, "present", ruleAt(validationErrors, "sm_id"))
+}
+
+func TestValidateStruct_NullNumericField(t *testing.T) {
+	order := decodePromoOrder(t, func(raw map[string]any) {
+		raw["payment"].(map[string]any)["amount"] = nil
+	})
+
+	err := ValidateStruct(order)
+	assert.Error(t, err)
+	assert.Equal(t, "present", ruleAt(GetValidationErrors(err), "payment.amount"))
+}
+
+func TestValidateStruct_NegativeValues(t *testing.T) {
+	order := decodePromoOrder(t, func(raw map[string]any) {
+		item := raw["items"].([]any)[0].(map[string]any)
+		item["price"] = -1
+		item["sale"] = 150
+	})
+
+	err := ValidateStruct(order)
+	assert.Error(t, err)
+
+	validationErrors := GetValidationErrors(err)
+	assert.Equal(t, "min", ruleAt(validationErrors, "items[0].price"))
+	assert.Equal(t, "max", ruleAt(validationErrors, "items[0].sale"))
+}
+
+func TestValidateStruct_UnknownStatus(t *testing.T) {
+	order := decodePromoOrder(t, func(raw map[string]any) {
+		raw["status"] = "lost"
+	})
+
+	err := ValidateStruct(order)
+	assert.Error(t, err)
+	validationErrors := GetValidationErrors(err)
+	assert.Equal(t, "oneof", ruleAt(validationErrors, "status"))
+	validationError, _ := findValidationError(validationErrors, "status")
+	assert.Contains(t, validationError.Message, "created, paid")
+}
+
+func TestGetValidationErrors_PathsDoNotCollide(t *testing.T) {
+	order := decodePromoOrder(t, func(raw map[string]any) {
+		items := raw["items"].([]any)
+		second := map[string]any{}
+		for k, v := range items[0].(map[string]any) {
+			second[k] = v
+		}
+		second["price"] = -5
+		raw["items"] = append(items, second)
+		raw["payment"].(map[string]any)["amount"] = -1
+	})
+
+	validationErrors := GetValidationErrors(ValidateStruct(order))
+	require.Len(t, validationErrors, 2)
+
+	price, ok := findValidationError(validationErrors, "items[1].price")
+	require.True(t, ok)
+	assert.Equal(t, "-5", price.Value)
+	assert.Equal(t, "must be greater than or equal to 0", price.Message)
+
+	_, ok = findValidationError(validationErrors, "payment.amount")
+	assert.True(t, ok)
+}
+
+func TestGetValidationErrors_MasksPII(t *testing.T) {
+	order := decodePromoOrder(t, func(raw map[string]any) {
+		raw["delivery"].(map[string]any)["email"] = "john.smith.example.com"
+	})
+
+	validationErrors := GetValidationErrors(ValidateStruct(order))
+	email, ok := findValidationError(validationErrors, "delivery.email")
+	require.True(t, ok)
+	assert.Equal(t, "email", email.Rule)
+	assert.Equal(t, "jo******************om", email.Value)
+}
+
+func TestGetValidationErrors_MasksPIIOfValidatedStruct(t *testing.T) {
+	type contact struct {
+		Phone string `json:"phone" validate:"min=10" pii:"true"`
+		Note  string `json:"note" validate:"min=10"`
+	}
+	type request struct {
+		Contact contact `json:"contact"`
+	}
+
+	validationErrors := GetValidationErrors(ValidateStruct(&request{Contact: contact{Phone: "+972000", Note: "short"}}))
+	phone, ok := findValidationError(validationErrors, "contact.phone")
+	require.True(t, ok)
+	assert.Equal(t, MaskValue("+972000"), phone.Value)
+	note, ok := findValidationError(validationErrors, "contact.note")
+	require.True(t, ok)
+	assert.Equal(t, "short", note.Value)
+}
+
+func TestPrefixPaths(t *testing.T) {
+	errs := PrefixPaths("[2]", []ValidationError{{Path: "items[0].price"}, {Path: "sm_id"}})
+	assert.Equal(t, "[2].items[0].price", errs[0].Path)
+	assert.Equal(t, "[2].sm_id", errs[1].Path)
 }